@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"self-payrol/config"
 	"self-payrol/delivery"
+	"self-payrol/model"
 	"self-payrol/repository"
+	"self-payrol/tax"
 	"self-payrol/usecase"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -15,8 +19,9 @@ import (
 
 type (
 	server struct {
-		httpServer *echo.Echo
-		cfg        config.Config
+		httpServer    *echo.Echo
+		cfg           config.Config
+		apiKeyUsecase model.ApiKeyUsecase
 	}
 
 	Server interface {
@@ -27,14 +32,22 @@ type (
 func InitServer(cfg config.Config) Server {
 	e := echo.New()
 	e.HideBanner = true
+	e.IPExtractor = delivery.IPExtractor(cfg.TrustedProxies())
+
+	apiKeyRepo := repository.NewApiKeyRepository(cfg)
+	apiKeyUsecase := usecase.NewApiKeyUsecase(apiKeyRepo)
 
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	e.Use(middleware.RequestID())
+	e.Use(delivery.RequestInfo())
+	e.Use(delivery.ApiKeyAuth(apiKeyUsecase))
 
 	return &server{
-		httpServer: e,
-		cfg:        cfg,
+		httpServer:    e,
+		cfg:           cfg,
+		apiKeyUsecase: apiKeyUsecase,
 	}
 }
 
@@ -48,31 +61,133 @@ func (s *server) Run() {
 		})
 	})
 
+	if s.cfg.JWTSecret() == "" {
+		log.Panic("JWT_SECRET is not set")
+	}
+
+	transactor := repository.NewTransactor(s.cfg)
+	transactionRepo := repository.NewTransactionRepository(s.cfg)
+
+	idempotencyRepo := repository.NewIdempotencyRepository(s.cfg)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(idempotencyRepo, s.cfg.IdempotencyRetention())
+
+	auditLogRepo := repository.NewAuditLogRepository(s.cfg)
+	auditLogUsecase := usecase.NewAuditLogUsecase(auditLogRepo)
+
 	positionRepo := repository.NewPositionRepository(s.cfg)
-	positionUsecase := usecase.NewPositionUsecase(positionRepo)
+	salaryVersionRepo := repository.NewSalaryVersionRepository(s.cfg)
+	positionUsecase := usecase.NewPositionUsecase(positionRepo, salaryVersionRepo, auditLogRepo, transactor)
+
+	companyRepo := repository.NewCompanyRepository(s.cfg)
+	companyUsecase := usecase.NewCompanyUsecase(companyRepo, transactionRepo, auditLogRepo, transactor)
+
+	userRepo := repository.NewUserRepository(s.cfg)
+	withdrawalRepo := repository.NewWithdrawalRepository(s.cfg)
+	secretAttemptRepo := repository.NewSecretAttemptRepository(s.cfg)
+	payComponentRepo := repository.NewPayComponentRepository(s.cfg)
+	payslipRepo := repository.NewPayslipRepository(s.cfg)
+	userUseCase := usecase.NewUserUsecase(userRepo, positionRepo, salaryVersionRepo, payComponentRepo, companyRepo,
+		withdrawalRepo, payslipRepo, transactor, secretAttemptRepo, auditLogRepo, s.cfg.SecretLockout(), tax.NewPPh21(),
+		s.cfg.Contributions())
+
+	payrollRunRepo := repository.NewPayrollRunRepository(s.cfg)
+	payrollRunUsecase := usecase.NewPayrollRunUsecase(payrollRunRepo, userRepo, salaryVersionRepo, payComponentRepo,
+		companyRepo, withdrawalRepo, payslipRepo, auditLogRepo, transactor, tax.NewPPh21(), s.cfg.Contributions())
+
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, companyRepo, withdrawalRepo, payslipRepo, transactor)
+
+	adminRepo := repository.NewAdminRepository(s.cfg)
+	adminUsecase := usecase.NewAdminUsecase(adminRepo, userUseCase, []byte(s.cfg.JWTSecret()), s.cfg.TokenTTL(),
+		s.cfg.ServiceName())
+	username, password := s.cfg.DefaultAdmin()
+	if err := adminUsecase.EnsureAdmin(context.Background(), username, password); err != nil {
+		log.Panic(err)
+	}
+
+	auth := delivery.Authenticated(adminUsecase)
+
+	authDelivery := delivery.NewAuthDelivery(adminUsecase, auth)
+	authGroup := s.httpServer.Group("/auth")
+	authDelivery.Mount(authGroup)
+
+	apiKeyDelivery := delivery.NewApiKeyDelivery(s.apiKeyUsecase)
+	apiKeyGroup := s.httpServer.Group("/api-keys", auth)
+	apiKeyDelivery.Mount(apiKeyGroup)
+
 	positionDelivery := delivery.NewPositionDelivery(positionUsecase)
-	positionGroup := s.httpServer.Group("/positions")
+	positionGroup := s.httpServer.Group("/positions", auth)
 	positionDelivery.Mount(positionGroup)
 
-	companyRepo := repository.NewCompanyRepository(s.cfg)
-	companyUsecase := usecase.NewCompanyUsecase(companyRepo)
-	companyDelivery := delivery.NewCompanyDelivery(companyUsecase)
-	companyGroup := s.httpServer.Group("/company")
+	companyDelivery := delivery.NewCompanyDelivery(companyUsecase, idempotencyUsecase, payrollRunUsecase)
+	companyGroup := s.httpServer.Group("/company", auth)
 	companyDelivery.Mount(companyGroup)
 
-	userRepo := repository.NewUserRepository(s.cfg)
-	userUseCase := usecase.NewUserUsecase(userRepo, positionRepo, companyRepo)
-	userDelivery := delivery.NewUserDelivery(userUseCase)
+	userDelivery := delivery.NewUserDelivery(userUseCase, idempotencyUsecase, auth)
 	userGroup := s.httpServer.Group("/employee")
 	userDelivery.Mount(userGroup)
 
-	transactionRepo := repository.NewTransactionRepository(s.cfg)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo)
+	meDelivery := delivery.NewMeDelivery(userUseCase, transactionUsecase)
+	meGroup := s.httpServer.Group("/me", auth)
+	meDelivery.Mount(meGroup)
+
 	transactionDelivery := delivery.NewTransactionDelivery(transactionUsecase)
-	transactionGroup := s.httpServer.Group("/transactions")
+	transactionGroup := s.httpServer.Group("/transactions", auth)
 	transactionDelivery.Mount(transactionGroup)
 
+	payrollRunDelivery := delivery.NewPayrollRunDelivery(payrollRunUsecase)
+	payrollRunGroup := s.httpServer.Group("/payroll-runs", auth)
+	payrollRunDelivery.Mount(payrollRunGroup)
+
+	auditLogDelivery := delivery.NewAuditLogDelivery(auditLogUsecase)
+	auditLogGroup := s.httpServer.Group("/audit-logs", auth)
+	auditLogDelivery.Mount(auditLogGroup)
+
+	go s.reconcileEvery(s.cfg.ReconciliationInterval(), companyUsecase)
+	go s.applySalaryChangesEvery(s.cfg.SalaryChangeInterval(), positionUsecase)
+
 	if err := s.httpServer.Start(fmt.Sprintf(":%d", s.cfg.ServicePort())); err != nil {
 		log.Panic(err)
 	}
 }
+
+// reconcileEvery checks the company balance against the ledger on every tick
+// and logs the discrepancies it finds. It does nothing when interval is zero.
+func (s *server) reconcileEvery(interval time.Duration, companyUsecase model.CompanyUsecase) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reconciliation, _, err := companyUsecase.Reconcile(context.Background())
+		if err != nil {
+			log.Printf("reconciliation failed: %s", err)
+			continue
+		}
+
+		if !reconciliation.Balanced {
+			log.Printf("company balance %d does not match ledger balance %d, discrepancy %d",
+				reconciliation.CompanyBalance, reconciliation.LedgerBalance, reconciliation.Discrepancy)
+		}
+	}
+}
+
+// applySalaryChangesEvery applies the scheduled salary changes that took
+// effect, once on start up and then on every tick.
+func (s *server) applySalaryChangesEvery(interval time.Duration, positionUsecase model.PositionUsecase) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := positionUsecase.ApplySalaryChanges(context.Background())
+		if err != nil {
+			log.Printf("applying salary changes failed: %s", err)
+		} else if applied > 0 {
+			log.Printf("applied %d scheduled salary changes", applied)
+		}
+
+		<-ticker.C
+	}
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"gorm.io/gorm"
+	"math"
+	"net"
 	"os"
 	"self-payrol/config/postgres"
+	"self-payrol/model"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type (
@@ -16,6 +21,15 @@ type (
 		ServicePort() int
 		ServiceEnvironment() string
 		Database() *gorm.DB
+		ReconciliationInterval() time.Duration
+		SalaryChangeInterval() time.Duration
+		IdempotencyRetention() time.Duration
+		SecretLockout() model.LockoutPolicy
+		TrustedProxies() []*net.IPNet
+		Contributions() []model.ContributionRule
+		JWTSecret() string
+		TokenTTL() time.Duration
+		DefaultAdmin() (username, password string)
 	}
 )
 
@@ -41,3 +55,141 @@ func (c *config) ServicePort() int {
 func (c *config) ServiceEnvironment() string {
 	return os.Getenv("ENV")
 }
+
+// ReconciliationInterval is how often the company balance is checked against
+// the ledger in the background. The check is disabled when it is not set.
+func (c *config) ReconciliationInterval() time.Duration {
+	v := os.Getenv("RECONCILIATION_INTERVAL")
+	interval, _ := time.ParseDuration(v)
+
+	return interval
+}
+
+// SalaryChangeInterval is how often scheduled salary changes that took effect
+// are applied to their positions, every hour unless configured.
+func (c *config) SalaryChangeInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("SALARY_CHANGE_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+
+	return interval
+}
+
+// IdempotencyRetention is how long a response is replayed for a repeated
+// Idempotency-Key, 24 hours unless configured.
+func (c *config) IdempotencyRetention() time.Duration {
+	v := os.Getenv("IDEMPOTENCY_RETENTION")
+	retention, err := time.ParseDuration(v)
+	if err != nil || retention <= 0 {
+		return 24 * time.Hour
+	}
+
+	return retention
+}
+
+// SecretLockout limits failed secret id checks on withdrawals. Unless
+// configured an employee is locked for 15 minutes after 5 failures and a
+// client IP after 20 failures.
+func (c *config) SecretLockout() model.LockoutPolicy {
+	policy := model.LockoutPolicy{
+		MaxAttempts:   5,
+		IPMaxAttempts: 20,
+		Cooldown:      15 * time.Minute,
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("SECRET_MAX_ATTEMPTS")); err == nil && v > 0 {
+		policy.MaxAttempts = v
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("SECRET_IP_MAX_ATTEMPTS")); err == nil && v > 0 {
+		policy.IPMaxAttempts = v
+	}
+
+	if v, err := time.ParseDuration(os.Getenv("SECRET_LOCKOUT_COOLDOWN")); err == nil && v > 0 {
+		policy.Cooldown = v
+	}
+
+	return policy
+}
+
+// TrustedProxies are the address ranges of the reverse proxies whose
+// X-Forwarded-For header is trusted for the client address, configured as
+// comma separated CIDRs. Ranges that do not parse are ignored. Without any the
+// client address is the one of the connection.
+func (c *config) TrustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+
+	for _, v := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if _, proxy, err := net.ParseCIDR(strings.TrimSpace(v)); err == nil {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
+// Contributions are the BPJS programs salaries contribute to. Unless
+// configured, BPJS Kesehatan takes 1% from the employee and 4% from the
+// employer on wages up to 12,000,000, JHT 2% and 3.7% uncapped, and JP 1% and
+// 2% on wages up to 10,547,400. Rates are configured in percent.
+func (c *config) Contributions() []model.ContributionRule {
+	return []model.ContributionRule{
+		contributionRule("BPJS_KESEHATAN", model.ContributionRule{
+			Name:         model.ContributionBPJSKesehatan,
+			EmployeeRate: 100,
+			EmployerRate: 400,
+			WageCap:      12000000,
+		}),
+		contributionRule("BPJS_JHT", model.ContributionRule{
+			Name:         model.ContributionBPJSJHT,
+			EmployeeRate: 200,
+			EmployerRate: 370,
+		}),
+		contributionRule("BPJS_JP", model.ContributionRule{
+			Name:         model.ContributionBPJSJP,
+			EmployeeRate: 100,
+			EmployerRate: 200,
+			WageCap:      10547400,
+		}),
+	}
+}
+
+// contributionRule overrides the rates and wage cap of rule with the
+// environment variables starting with prefix.
+func contributionRule(prefix string, rule model.ContributionRule) model.ContributionRule {
+	if v, err := strconv.ParseFloat(os.Getenv(prefix+"_EMPLOYEE_RATE"), 64); err == nil && v >= 0 {
+		rule.EmployeeRate = int(math.Round(v * 100))
+	}
+
+	if v, err := strconv.ParseFloat(os.Getenv(prefix+"_EMPLOYER_RATE"), 64); err == nil && v >= 0 {
+		rule.EmployerRate = int(math.Round(v * 100))
+	}
+
+	if v, err := strconv.Atoi(os.Getenv(prefix + "_WAGE_CAP")); err == nil && v >= 0 {
+		rule.WageCap = v
+	}
+
+	return rule
+}
+
+// JWTSecret is the key admin access tokens are signed with.
+func (c *config) JWTSecret() string {
+	return os.Getenv("JWT_SECRET")
+}
+
+// TokenTTL is how long an admin access token is valid, one hour unless
+// configured.
+func (c *config) TokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("JWT_TTL"))
+	if err != nil || ttl <= 0 {
+		return time.Hour
+	}
+
+	return ttl
+}
+
+// DefaultAdmin is the admin created on start up while there is none yet.
+func (c *config) DefaultAdmin() (username, password string) {
+	return os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD")
+}
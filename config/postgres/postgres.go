@@ -16,7 +16,10 @@ func InitGorm() *gorm.DB {
 	if err != nil {
 		log.Error().Msgf("cant connect to database %s", err)
 	}
-	db.AutoMigrate(&model.Position{}, &model.User{}, &model.Company{}, &model.Transaction{})
+	db.AutoMigrate(&model.Position{}, &model.User{}, &model.Company{}, &model.Transaction{}, &model.Withdrawal{},
+		&model.IdempotencyKey{}, &model.SecretAttempt{},
+		&model.Admin{}, &model.ApiKey{}, &model.AuditLog{}, &model.SalaryVersion{}, &model.PayComponent{},
+		&model.Payslip{}, &model.PayrollRun{}, &model.PayrollRunItem{})
 
 	return db
 
@@ -0,0 +1,109 @@
+package delivery
+
+import (
+	"net/http"
+	"self-payrol/helper"
+	"self-payrol/model"
+	"self-payrol/request"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+const apiKeyScheme = "ApiKey "
+
+type apiKeyDelivery struct {
+	apiKeyUsecase model.ApiKeyUsecase
+}
+
+type ApiKeyDelivery interface {
+	Mount(group *echo.Group)
+}
+
+func NewApiKeyDelivery(apiKeyUsecase model.ApiKeyUsecase) ApiKeyDelivery {
+	return &apiKeyDelivery{apiKeyUsecase: apiKeyUsecase}
+}
+
+func (p *apiKeyDelivery) Mount(group *echo.Group) {
+	manage := Authorize(model.PermissionManageApiKeys)
+
+	group.GET("", p.FetchApiKeyHandler, manage)
+	group.POST("", p.StoreApiKeyHandler, manage)
+	group.DELETE("/:id", p.RevokeApiKeyHandler, manage)
+}
+
+func (p *apiKeyDelivery) FetchApiKeyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	keys, err := p.apiKeyUsecase.Fetch(ctx, limit, offset)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusInternalServerError, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", keys)
+}
+
+func (p *apiKeyDelivery) StoreApiKeyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.ApiKeyRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	key, err := p.apiKeyUsecase.Create(ctx, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", key)
+}
+
+func (p *apiKeyDelivery) RevokeApiKeyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	if err := p.apiKeyUsecase.Revoke(ctx, IdInt); err != nil {
+		return helper.ResponseErrorJson(c, http.StatusNotFound, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "Success revoke api key", "")
+}
+
+// ApiKeyAuth authenticates requests carrying an "Authorization: ApiKey <key>"
+// header and puts the key's claims in the request context, where
+// Authenticated and Authorize pick them up. Other requests pass untouched.
+func ApiKeyAuth(apiKeyUsecase model.ApiKeyUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(header, apiKeyScheme) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+
+			claims, err := apiKeyUsecase.Authenticate(ctx, strings.TrimPrefix(header, apiKeyScheme))
+			if err != nil {
+				return helper.ResponseErrorJson(c, http.StatusUnauthorized, err)
+			}
+
+			c.SetRequest(c.Request().WithContext(model.ContextWithClaims(ctx, claims)))
+
+			return next(c)
+		}
+	}
+}
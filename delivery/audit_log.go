@@ -0,0 +1,65 @@
+package delivery
+
+import (
+	"self-payrol/helper"
+	"self-payrol/model"
+	"self-payrol/request"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type auditLogDelivery struct {
+	auditLogUsecase model.AuditLogUsecase
+}
+
+type AuditLogDelivery interface {
+	Mount(group *echo.Group)
+}
+
+func NewAuditLogDelivery(auditLogUsecase model.AuditLogUsecase) AuditLogDelivery {
+	return &auditLogDelivery{auditLogUsecase: auditLogUsecase}
+}
+
+func (p *auditLogDelivery) Mount(group *echo.Group) {
+	group.GET("", p.FetchAuditLogHandler, Authorize(model.PermissionReadAuditLogs))
+}
+
+func (p *auditLogDelivery) FetchAuditLogHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var filter request.AuditLogFilter
+
+	if err := c.Bind(&filter); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := filter.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	logs, meta, i, err := p.auditLogUsecase.Fetch(ctx, filter)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessWithMetaJson(c, "success", logs, meta)
+}
+
+// RequestInfo stores the request id and client address of the request in its
+// context for the audit logs. It expects the request id to be set on the
+// response by middleware.RequestID.
+func RequestInfo() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := model.ContextWithRequestInfo(c.Request().Context(), &model.RequestInfo{
+				ID: c.Response().Header().Get(echo.HeaderXRequestID),
+				IP: c.RealIP(),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package delivery
+
+import (
+	"net/http"
+	"self-payrol/helper"
+	"self-payrol/model"
+	"self-payrol/request"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+const authScheme = "Bearer "
+
+type authDelivery struct {
+	adminUsecase model.AdminUsecase
+	auth         echo.MiddlewareFunc
+}
+
+type AuthDelivery interface {
+	Mount(group *echo.Group)
+}
+
+func NewAuthDelivery(adminUsecase model.AdminUsecase, auth echo.MiddlewareFunc) AuthDelivery {
+	return &authDelivery{adminUsecase: adminUsecase, auth: auth}
+}
+
+func (p *authDelivery) Mount(group *echo.Group) {
+	group.POST("/login", p.LoginHandler)
+	group.POST("/employee/login", p.EmployeeLoginHandler)
+	group.POST("/admins", p.StoreAdminHandler, p.auth, Authorize(model.PermissionManageAdmins))
+}
+
+func (p *authDelivery) LoginHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.LoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	token, err := p.adminUsecase.Login(ctx, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusInternalServerError, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", token)
+}
+
+func (p *authDelivery) EmployeeLoginHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.EmployeeLoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	req.IP = c.RealIP()
+
+	token, err := p.adminUsecase.EmployeeLogin(ctx, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnauthorized, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", token)
+}
+
+func (p *authDelivery) StoreAdminHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.AdminRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	admin, err := p.adminUsecase.StoreAdmin(ctx, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", admin)
+}
+
+// Authenticated only lets requests through that carry a valid access token in
+// the Authorization header. The token claims are put in the request context
+// for Authorize and the handlers. Requests already authenticated by ApiKeyAuth
+// are let through as they are.
+func Authenticated(adminUsecase model.AdminUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if model.ClaimsFromContext(c.Request().Context()) != nil {
+				return next(c)
+			}
+
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(header, authScheme) {
+				return helper.ResponseErrorJson(c, http.StatusUnauthorized, model.ErrUnauthorized)
+			}
+
+			ctx := c.Request().Context()
+
+			claims, err := adminUsecase.Authenticate(ctx, strings.TrimPrefix(header, authScheme))
+			if err != nil {
+				return helper.ResponseErrorJson(c, http.StatusUnauthorized, err)
+			}
+
+			c.SetRequest(c.Request().WithContext(model.ContextWithClaims(ctx, claims)))
+
+			return next(c)
+		}
+	}
+}
+
+// Authorize only lets requests through whose access token role is granted
+// permission. It must run after Authenticated.
+func Authorize(permission model.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := model.ClaimsFromContext(c.Request().Context())
+			if claims == nil {
+				return helper.ResponseErrorJson(c, http.StatusUnauthorized, model.ErrUnauthorized)
+			}
+
+			if !claims.Can(permission) {
+				return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AuthorizeOwner works like Authorize but also lets an employee through to
+// the routes about themselves, that is when the :id parameter is their own
+// user id.
+func AuthorizeOwner(permission model.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := model.ClaimsFromContext(c.Request().Context())
+			if claims == nil {
+				return helper.ResponseErrorJson(c, http.StatusUnauthorized, model.ErrUnauthorized)
+			}
+
+			if claims.Can(permission) {
+				return next(c)
+			}
+
+			id, _ := strconv.Atoi(c.Param("id"))
+			if claims.Can(model.PermissionReadOwnProfile) && claims.UserID != 0 && claims.UserID == id {
+				return next(c)
+			}
+
+			return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+		}
+	}
+}
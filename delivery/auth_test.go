@@ -0,0 +1,331 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"self-payrol/model"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenUsecase accepts the tokens listed in claims.
+type tokenUsecase struct {
+	model.AdminUsecase
+	claims map[string]*model.Claims
+}
+
+func (u *tokenUsecase) Authenticate(ctx context.Context, token string) (*model.Claims, error) {
+	claims, ok := u.claims[token]
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+
+	return claims, nil
+}
+
+// keyUsecase accepts the api keys listed in claims.
+type keyUsecase struct {
+	model.ApiKeyUsecase
+	claims map[string]*model.Claims
+}
+
+func (u *keyUsecase) Authenticate(ctx context.Context, key string) (*model.Claims, error) {
+	claims, ok := u.claims[key]
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+
+	return claims, nil
+}
+
+func newTestServer() *echo.Echo {
+	auth := Authenticated(&tokenUsecase{claims: map[string]*model.Claims{
+		"admin":    {Username: "admin", Role: model.RoleAdmin},
+		"hr":       {Username: "hr", Role: model.RoleHR},
+		"finance":  {Username: "finance", Role: model.RoleFinance},
+		"employee": {Username: "employee", Role: model.RoleEmployee, UserID: 7},
+	}})
+
+	e := echo.New()
+	e.Use(ApiKeyAuth(&keyUsecase{claims: map[string]*model.Claims{
+		"spk_accounting": {Username: "accounting", Scopes: []model.Permission{model.PermissionReadTransactions}},
+	}}))
+	NewApiKeyDelivery(nil).Mount(e.Group("/api-keys", auth))
+	NewPositionDelivery(nil).Mount(e.Group("/positions", auth))
+	NewCompanyDelivery(nil, nil, nil).Mount(e.Group("/company", auth))
+	NewUserDelivery(nil, nil, auth).Mount(e.Group("/employee"))
+	NewTransactionDelivery(nil).Mount(e.Group("/transactions", auth))
+	NewAuthDelivery(nil, auth).Mount(e.Group("/auth"))
+	NewMeDelivery(nil, nil).Mount(e.Group("/me", auth))
+	NewAuditLogDelivery(nil).Mount(e.Group("/audit-logs", auth))
+
+	return e
+}
+
+func TestForbiddenAccess(t *testing.T) {
+	e := newTestServer()
+	tests := []struct {
+		name           string
+		token          string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "should refuse request without token",
+			method:         http.MethodGet,
+			path:           "/positions",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should refuse invalid token",
+			token:          "unknown",
+			method:         http.MethodGet,
+			path:           "/company",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should forbid finance to manage positions",
+			token:          "finance",
+			method:         http.MethodPost,
+			path:           "/positions",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid finance to delete employees",
+			token:          "finance",
+			method:         http.MethodDelete,
+			path:           "/employee/1",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid finance to update the company",
+			token:          "finance",
+			method:         http.MethodPost,
+			path:           "/company",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid finance to adjust the company balance",
+			token:          "finance",
+			method:         http.MethodPost,
+			path:           "/company/balance-adjustments",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid finance to reverse transactions",
+			token:          "finance",
+			method:         http.MethodPost,
+			path:           "/transactions/1/reverse",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid hr to top up",
+			token:          "hr",
+			method:         http.MethodPost,
+			path:           "/company/topup",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid hr to read transactions",
+			token:          "hr",
+			method:         http.MethodGet,
+			path:           "/transactions",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid hr to create admins",
+			token:          "hr",
+			method:         http.MethodPost,
+			path:           "/auth/admins",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid hr to read audit logs",
+			token:          "hr",
+			method:         http.MethodGet,
+			path:           "/audit-logs",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid employee to list employees",
+			token:          "employee",
+			method:         http.MethodGet,
+			path:           "/employee",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid employee to read another profile",
+			token:          "employee",
+			method:         http.MethodGet,
+			path:           "/employee/8",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid employee to read another withdrawal history",
+			token:          "employee",
+			method:         http.MethodGet,
+			path:           "/employee/8/withdrawals",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid employee to read positions",
+			token:          "employee",
+			method:         http.MethodGet,
+			path:           "/positions",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid finance to use the employee self-service",
+			token:          "finance",
+			method:         http.MethodGet,
+			path:           "/me",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid admin without employee account to use the self-service",
+			token:          "admin",
+			method:         http.MethodGet,
+			path:           "/me/next-withdrawal",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should refuse unknown api key",
+			token:          "spk_unknown",
+			method:         http.MethodGet,
+			path:           "/transactions",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should forbid api key to act outside its scopes",
+			token:          "spk_accounting",
+			method:         http.MethodPost,
+			path:           "/transactions/1/reverse",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid api key to manage employees without scope",
+			token:          "spk_accounting",
+			method:         http.MethodDelete,
+			path:           "/employee/1",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid hr to manage api keys",
+			token:          "hr",
+			method:         http.MethodPost,
+			path:           "/api-keys",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid employee to read the company",
+			token:          "employee",
+			method:         http.MethodGet,
+			path:           "/company",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if strings.HasPrefix(test.token, "spk_") {
+				req.Header.Set(echo.HeaderAuthorization, "ApiKey "+test.token)
+			} else if test.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         *model.Claims
+		middleware     echo.MiddlewareFunc
+		id             string
+		expectedStatus int
+	}{
+		{
+			name:           "should let admin through",
+			claims:         &model.Claims{Role: model.RoleAdmin},
+			middleware:     Authorize(model.PermissionManageEmployees),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should let granted role through",
+			claims:         &model.Claims{Role: model.RoleFinance},
+			middleware:     Authorize(model.PermissionTopup),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should forbid role without permission",
+			claims:         &model.Claims{Role: model.RoleHR},
+			middleware:     Authorize(model.PermissionTopup),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should refuse request without claims",
+			middleware:     Authorize(model.PermissionTopup),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "should let employee read own profile",
+			claims:         &model.Claims{Role: model.RoleEmployee, UserID: 7},
+			middleware:     AuthorizeOwner(model.PermissionManageEmployees),
+			id:             "7",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should forbid employee to read another profile",
+			claims:         &model.Claims{Role: model.RoleEmployee, UserID: 7},
+			middleware:     AuthorizeOwner(model.PermissionManageEmployees),
+			id:             "8",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should forbid staff without permission to read any profile",
+			claims:         &model.Claims{Role: model.RoleFinance},
+			middleware:     AuthorizeOwner(model.PermissionManageEmployees),
+			id:             "0",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "should let hr read any profile",
+			claims:         &model.Claims{Role: model.RoleHR},
+			middleware:     AuthorizeOwner(model.PermissionManageEmployees),
+			id:             "8",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.claims != nil {
+				req = req.WithContext(model.ContextWithClaims(req.Context(), test.claims))
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(test.id)
+
+			_ = test.middleware(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+
+			assert.Equal(t, test.expectedStatus, rec.Code)
+		})
+	}
+}
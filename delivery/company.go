@@ -1,30 +1,47 @@
 package delivery
 
 import (
+	"fmt"
 	"self-payrol/helper"
 	"self-payrol/model"
 	"self-payrol/request"
+	"strconv"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/labstack/echo/v4"
 )
 
 type companyDelivery struct {
-	companyUsecase model.CompanyUsecase
+	companyUsecase     model.CompanyUsecase
+	idempotencyUsecase model.IdempotencyUsecase
+	payrollRunUsecase  model.PayrollRunUsecase
 }
 
 type CompanyDelivery interface {
 	Mount(group *echo.Group)
 }
 
-func NewCompanyDelivery(companyUsecase model.CompanyUsecase) CompanyDelivery {
-	return &companyDelivery{companyUsecase: companyUsecase}
+func NewCompanyDelivery(companyUsecase model.CompanyUsecase, idempotencyUsecase model.IdempotencyUsecase,
+	payrollRunUsecase model.PayrollRunUsecase) CompanyDelivery {
+	return &companyDelivery{
+		companyUsecase:     companyUsecase,
+		idempotencyUsecase: idempotencyUsecase,
+		payrollRunUsecase:  payrollRunUsecase,
+	}
 }
 
 func (comp *companyDelivery) Mount(group *echo.Group) {
-	group.GET("", comp.GetDetailCompanyHandler)
-	group.POST("", comp.UpdateOrCreateCompanyHandler)
-	group.POST("/topup", comp.TopupBalanceHandler)
+	read := Authorize(model.PermissionReadCompany)
+	manage := Authorize(model.PermissionManageCompany)
+
+	group.GET("", comp.GetDetailCompanyHandler, read)
+	group.POST("", comp.UpdateOrCreateCompanyHandler, manage)
+	group.POST("/topup", comp.TopupBalanceHandler, Authorize(model.PermissionTopup), Idempotency(comp.idempotencyUsecase, ClaimsPrincipal))
+	group.POST("/balance-adjustments", comp.AdjustBalanceHandler, manage)
+	group.GET("/statement", comp.StatementHandler, read)
+	group.GET("/reconciliation", comp.ReconciliationHandler, read)
+	group.GET("/forecast", comp.ForecastHandler, read)
+	group.POST("/reconciliation/adjustments", comp.AdjustmentHandler, manage)
 
 }
 
@@ -83,3 +100,141 @@ func (comp *companyDelivery) TopupBalanceHandler(e echo.Context) error {
 
 	return helper.ResponseSuccessJson(e, "success", company)
 }
+
+func (comp *companyDelivery) AdjustBalanceHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	var req request.BalanceAdjustmentRequest
+
+	if err := e.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(e, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(e, "Error validation", errVal)
+	}
+
+	company, i, err := comp.companyUsecase.AdjustBalance(ctx, req)
+	if err != nil {
+		return helper.ResponseErrorJson(e, i, err)
+	}
+
+	return helper.ResponseSuccessJson(e, "success", company)
+}
+
+func (comp *companyDelivery) StatementHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	var req request.StatementRequest
+
+	if err := e.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(e, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(e, "Error validation", errVal)
+	}
+
+	statement, i, err := comp.companyUsecase.GetStatement(ctx, req)
+	if err != nil {
+		return helper.ResponseErrorJson(e, i, err)
+	}
+
+	if req.Format == "csv" {
+		filename := fmt.Sprintf("statement-%s-%s.csv", req.From, req.To)
+		return helper.ResponseCSV(e, filename, statementRecords(statement))
+	}
+
+	return helper.ResponseSuccessJson(e, "success", statement)
+}
+
+func (comp *companyDelivery) ReconciliationHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	reconciliation, i, err := comp.companyUsecase.Reconcile(ctx)
+	if err != nil {
+		return helper.ResponseErrorJson(e, i, err)
+	}
+
+	return helper.ResponseSuccessJson(e, "success", reconciliation)
+}
+
+func (comp *companyDelivery) ForecastHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	var req request.ForecastRequest
+
+	if err := e.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(e, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(e, "Error validation", errVal)
+	}
+
+	forecast, i, err := comp.payrollRunUsecase.Forecast(ctx, req)
+	if err != nil {
+		return helper.ResponseErrorJson(e, i, err)
+	}
+
+	return helper.ResponseSuccessJson(e, "success", forecast)
+}
+
+func (comp *companyDelivery) AdjustmentHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+
+	var req request.AdjustmentRequest
+
+	if err := e.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(e, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(e, "Error validation", errVal)
+	}
+
+	reconciliation, i, err := comp.companyUsecase.AdjustToBalance(ctx, req)
+	if err != nil {
+		return helper.ResponseErrorJson(e, i, err)
+	}
+
+	return helper.ResponseSuccessJson(e, "success", reconciliation)
+}
+
+// statementRecords lays the statement out as CSV rows, framing the entries
+// with the opening and closing balance.
+func statementRecords(statement *model.Statement) [][]string {
+	amount := func(v int) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	}
+
+	records := [][]string{
+		{"date", "transaction_id", "reference", "description", "category", "debit", "credit", "balance"},
+		{statement.From, "", "", "Opening balance", "", "", "", strconv.Itoa(statement.OpeningBalance)},
+	}
+
+	for _, entry := range statement.Entries {
+		records = append(records, []string{
+			entry.Date.Format("2006-01-02 15:04:05"),
+			strconv.Itoa(entry.TransactionID),
+			entry.Reference,
+			entry.Description,
+			entry.Category,
+			amount(entry.Debit),
+			amount(entry.Credit),
+			strconv.Itoa(entry.Balance),
+		})
+	}
+
+	return append(records, []string{
+		statement.To, "", "", "Closing balance", "",
+		strconv.Itoa(statement.TotalDebit), strconv.Itoa(statement.TotalCredit), strconv.Itoa(statement.ClosingBalance),
+	})
+}
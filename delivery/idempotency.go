@@ -0,0 +1,101 @@
+package delivery
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"self-payrol/helper"
+	"self-payrol/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// responseRecorder keeps a copy of the body written by the handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// IdempotencyPrincipal names who sends the request with body to an idempotent
+// route. Keys are scoped to it, so one caller reusing the key of another is
+// neither replayed their response nor blocks their request.
+type IdempotencyPrincipal func(c echo.Context, body []byte) string
+
+// ClaimsPrincipal names the caller by the access token or API key the request
+// was authenticated with.
+func ClaimsPrincipal(c echo.Context, body []byte) string {
+	if claims := model.ClaimsFromContext(c.Request().Context()); claims != nil {
+		return claims.Actor()
+	}
+
+	return ""
+}
+
+// Idempotency makes a route safe to retry. A request carrying an
+// Idempotency-Key header is handled once, repeating it with the same body
+// replays the stored response and reusing the key with another body is
+// refused. Keys are scoped to the route and the caller named by principal.
+// Requests without the header are passed through untouched.
+func Idempotency(idempotencyUsecase model.IdempotencyUsecase, principal IdempotencyPrincipal) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return helper.ResponseValidationErrorJson(c, "Error reading body", err.Error())
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := sha256.Sum256(body)
+			scope := c.Request().Method + " " + c.Path() + " " + principal(c, body)
+			ctx := c.Request().Context()
+
+			record, err := idempotencyUsecase.Begin(ctx, key, scope, hex.EncodeToString(hash[:]))
+			if err != nil {
+				return helper.ResponseErrorJson(c, http.StatusInternalServerError, err)
+			}
+
+			if record.Completed() {
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.Blob(record.StatusCode, echo.MIMEApplicationJSONCharsetUTF8, record.Response)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			c.SetRequest(c.Request().WithContext(model.ContextWithIdempotencyKey(ctx, key)))
+
+			handlerErr := next(c)
+
+			status := c.Response().Status
+			if !c.Response().Committed || status >= http.StatusInternalServerError {
+				if err := idempotencyUsecase.Release(ctx, record); err != nil {
+					c.Logger().Error(err)
+				}
+
+				return handlerErr
+			}
+
+			if err := idempotencyUsecase.Complete(ctx, record, status, recorder.body.Bytes()); err != nil {
+				c.Logger().Error(err)
+			}
+
+			return handlerErr
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"self-payrol/model"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotency keeps idempotency keys in memory by key and scope.
+type memoryIdempotency struct {
+	keys map[string]*model.IdempotencyKey
+}
+
+func (m *memoryIdempotency) Begin(ctx context.Context, key, scope, requestHash string) (*model.IdempotencyKey, error) {
+	if existing, ok := m.keys[key+" "+scope]; ok {
+		if existing.RequestHash != requestHash {
+			return nil, model.ErrIdempotencyKeyReused
+		}
+
+		return existing, nil
+	}
+
+	record := &model.IdempotencyKey{Key: key, Scope: scope, RequestHash: requestHash}
+	m.keys[key+" "+scope] = record
+
+	return record, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, key *model.IdempotencyKey, statusCode int, response []byte) error {
+	key.StatusCode = statusCode
+	key.Response = response
+
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, key *model.IdempotencyKey) error {
+	delete(m.keys, key.Key+" "+key.Scope)
+
+	return nil
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	auth := Authenticated(&tokenUsecase{claims: map[string]*model.Claims{
+		"finance":       {Username: "finance", Role: model.RoleFinance, StandardClaims: jwt.StandardClaims{Subject: "1"}},
+		"other-finance": {Username: "other", Role: model.RoleFinance, StandardClaims: jwt.StandardClaims{Subject: "2"}},
+	}})
+	idempotency := &memoryIdempotency{keys: map[string]*model.IdempotencyKey{}}
+	handled := 0
+	handler := func(c echo.Context) error {
+		handled++
+		return c.String(http.StatusOK, model.ActorFromContext(c.Request().Context()))
+	}
+
+	e := echo.New()
+	e.POST("/company/topup", handler, auth, Idempotency(idempotency, ClaimsPrincipal))
+	e.POST("/employee/withdraw", handler, Idempotency(idempotency, withdrawPrincipal))
+
+	tests := []struct {
+		name             string
+		token            string
+		path             string
+		body             string
+		expectedBody     string
+		expectedReplayed bool
+		expectedHandled  int
+	}{
+		{
+			name:            "should handle the first top-up",
+			token:           "finance",
+			path:            "/company/topup",
+			body:            `{"balance":100000}`,
+			expectedBody:    "admin:1",
+			expectedHandled: 1,
+		},
+		{
+			name:             "should replay the top-up retried by the same caller",
+			token:            "finance",
+			path:             "/company/topup",
+			body:             `{"balance":100000}`,
+			expectedBody:     "admin:1",
+			expectedReplayed: true,
+			expectedHandled:  1,
+		},
+		{
+			name:            "should handle the top-up of another caller with the same key",
+			token:           "other-finance",
+			path:            "/company/topup",
+			body:            `{"balance":100000}`,
+			expectedBody:    "admin:2",
+			expectedHandled: 2,
+		},
+		{
+			name:            "should handle the first withdrawal",
+			path:            "/employee/withdraw",
+			body:            `{"id":7,"secret_id":"secret"}`,
+			expectedBody:    "system",
+			expectedHandled: 3,
+		},
+		{
+			name:             "should replay the withdrawal retried by the same employee",
+			path:             "/employee/withdraw",
+			body:             `{"id":7,"secret_id":"secret"}`,
+			expectedBody:     "system",
+			expectedReplayed: true,
+			expectedHandled:  3,
+		},
+		{
+			name:            "should handle the withdrawal of another employee with the same key",
+			path:            "/employee/withdraw",
+			body:            `{"id":8,"secret_id":"secret"}`,
+			expectedBody:    "system",
+			expectedHandled: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderIdempotencyKey, "f1c9a3e2")
+			if test.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, test.expectedBody, rec.Body.String())
+			assert.Equal(t, test.expectedReplayed, rec.Header().Get(HeaderIdempotentReplayed) == "true")
+			assert.Equal(t, test.expectedHandled, handled)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package delivery
+
+import (
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPExtractor reads the client address secret id attempts are throttled by.
+// X-Forwarded-For is only trusted when the request comes from one of proxies,
+// otherwise clients could dodge the throttling by rotating the header.
+func IPExtractor(proxies []*net.IPNet) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,45 @@
+package delivery
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPExtractor(t *testing.T) {
+	_, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+	_, otherProxy, _ := net.ParseCIDR("192.168.0.0/16")
+	tests := []struct {
+		name     string
+		proxies  []*net.IPNet
+		expected string
+	}{
+		{
+			name:     "should ignore X-Forwarded-For without trusted proxies",
+			expected: "10.0.0.1",
+		},
+		{
+			name:     "should read X-Forwarded-For from a trusted proxy",
+			proxies:  []*net.IPNet{proxy},
+			expected: "203.0.113.7",
+		},
+		{
+			name:     "should ignore X-Forwarded-For from another proxy",
+			proxies:  []*net.IPNet{otherProxy},
+			expected: "10.0.0.1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/employee/withdraw", nil)
+			req.RemoteAddr = "10.0.0.1:4321"
+			req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+
+			assert.Equal(t, test.expected, IPExtractor(test.proxies)(req))
+		})
+	}
+}
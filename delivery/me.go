@@ -0,0 +1,151 @@
+package delivery
+
+import (
+	"net/http"
+	"self-payrol/helper"
+	"self-payrol/model"
+	"self-payrol/request"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type meDelivery struct {
+	userUsecase        model.UserUsecase
+	transactionUsecase model.TransactionUsecase
+}
+
+type MeDelivery interface {
+	Mount(group *echo.Group)
+}
+
+func NewMeDelivery(userUsecase model.UserUsecase, transactionUsecase model.TransactionUsecase) MeDelivery {
+	return &meDelivery{userUsecase: userUsecase, transactionUsecase: transactionUsecase}
+}
+
+// Mount registers the self-service routes of the employee the access token
+// was issued to.
+func (p *meDelivery) Mount(group *echo.Group) {
+	readOwn := Authorize(model.PermissionReadOwnProfile)
+
+	group.GET("", p.ProfileHandler, readOwn)
+	group.GET("/withdrawals", p.WithdrawalHistoryHandler, readOwn)
+	group.GET("/next-withdrawal", p.NextWithdrawalHandler, readOwn)
+	group.GET("/tax-summary/:year", p.TaxSummaryHandler, readOwn)
+	group.GET("/payslips/:period", p.PayslipHandler, readOwn)
+}
+
+func (p *meDelivery) ProfileHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, ok := employeeID(c)
+	if !ok {
+		return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+	}
+
+	user, err := p.userUsecase.GetByID(ctx, id)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", user)
+}
+
+// WithdrawalHistoryHandler lists the salary withdrawal entries of the ledger,
+// including the rejected ones, newest first.
+func (p *meDelivery) WithdrawalHistoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, ok := employeeID(c)
+	if !ok {
+		return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	transactions, meta, i, err := p.transactionUsecase.Fetch(ctx, request.TransactionFilter{
+		Category: model.TransactionCategorySalaryWithdrawal,
+		UserID:   id,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessWithMetaJson(c, "success", transactions, meta)
+}
+
+func (p *meDelivery) NextWithdrawalHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, ok := employeeID(c)
+	if !ok {
+		return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+	}
+
+	eligibility, err := p.userUsecase.NextWithdrawal(ctx, id)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", eligibility)
+}
+
+func (p *meDelivery) TaxSummaryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, ok := employeeID(c)
+	if !ok {
+		return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+	}
+
+	year, _ := strconv.Atoi(c.Param("year"))
+
+	summary, err := p.userUsecase.TaxSummary(ctx, id, year)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", summary)
+}
+
+func (p *meDelivery) PayslipHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, ok := employeeID(c)
+	if !ok {
+		return helper.ResponseErrorJson(c, http.StatusForbidden, model.ErrForbidden)
+	}
+
+	var req request.PayslipRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	payslip, err := p.userUsecase.Payslip(ctx, id, c.Param("period"))
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return responsePayslip(c, req.Format, payslip)
+}
+
+// employeeID returns the user id of the employee the access token was issued
+// to. Tokens issued to admins carry none.
+func employeeID(c echo.Context) (int, bool) {
+	claims := model.ClaimsFromContext(c.Request().Context())
+	if claims == nil || claims.UserID == 0 {
+		return 0, false
+	}
+
+	return claims.UserID, true
+}
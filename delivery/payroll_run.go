@@ -0,0 +1,129 @@
+package delivery
+
+import (
+	"self-payrol/helper"
+	"self-payrol/model"
+	"self-payrol/request"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/labstack/echo/v4"
+)
+
+type payrollRunDelivery struct {
+	payrollRunUsecase model.PayrollRunUsecase
+}
+
+type PayrollRunDelivery interface {
+	Mount(group *echo.Group)
+}
+
+func NewPayrollRunDelivery(payrollRunUsecase model.PayrollRunUsecase) PayrollRunDelivery {
+	return &payrollRunDelivery{payrollRunUsecase: payrollRunUsecase}
+}
+
+func (p *payrollRunDelivery) Mount(group *echo.Group) {
+	manage := Authorize(model.PermissionManagePayroll)
+
+	group.GET("", p.FetchPayrollRunHandler, manage)
+	group.POST("", p.CreatePayrollRunHandler, manage)
+	group.GET("/:id", p.DetailPayrollRunHandler, manage)
+	group.POST("/:id/approve", p.ApprovePayrollRunHandler, manage)
+	group.POST("/:id/execute", p.ExecutePayrollRunHandler, manage)
+	group.POST("/:id/cancel", p.CancelPayrollRunHandler, manage)
+}
+
+func (p *payrollRunDelivery) FetchPayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit := c.QueryParam("limit")
+	offset := c.QueryParam("offset")
+
+	limitInt, _ := strconv.Atoi(limit)
+	offsetInt, _ := strconv.Atoi(offset)
+
+	runs, i, err := p.payrollRunUsecase.Fetch(ctx, limitInt, offsetInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", runs)
+}
+
+func (p *payrollRunDelivery) CreatePayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.PayrollRunRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	run, i, err := p.payrollRunUsecase.Create(ctx, req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", run)
+}
+
+func (p *payrollRunDelivery) DetailPayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	run, i, err := p.payrollRunUsecase.GetByID(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", run)
+}
+
+func (p *payrollRunDelivery) ApprovePayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	run, i, err := p.payrollRunUsecase.Approve(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", run)
+}
+
+func (p *payrollRunDelivery) ExecutePayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	run, i, err := p.payrollRunUsecase.Execute(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", run)
+}
+
+func (p *payrollRunDelivery) CancelPayrollRunHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	run, i, err := p.payrollRunUsecase.Cancel(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", run)
+}
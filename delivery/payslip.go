@@ -0,0 +1,90 @@
+package delivery
+
+import (
+	"bytes"
+	"html/template"
+	"self-payrol/helper"
+	"self-payrol/model"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// payslipTemplate lays a payslip out as a printable page.
+var payslipTemplate = template.Must(template.New("payslip").Funcs(template.FuncMap{
+	"rupiah": rupiah,
+	"date":   func(t time.Time) string { return t.Format("2 January 2006") },
+	"before": func(t time.Time) time.Time { return t.AddDate(0, 0, -1) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Payslip {{.Period}} - {{.EmployeeName}}</title>
+<style>
+body { font-family: sans-serif; max-width: 640px; margin: 2em auto; }
+table { width: 100%; border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { padding: 4px 0; text-align: left; }
+td.amount, th.amount { text-align: right; }
+tr.total { border-top: 1px solid #000; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.CompanyName}}</h1>
+<p>{{.CompanyAddress}}</p>
+<h2>Payslip {{.Period}}</h2>
+<table>
+<tr><th>Employee</th><td>{{.EmployeeName}}</td></tr>
+<tr><th>Position</th><td>{{.PositionName}}</td></tr>
+<tr><th>Period</th><td>{{date .PeriodStart}} - {{date (before .PeriodEnd)}}</td></tr>
+</table>
+<table>
+<tr><th>Earnings</th><th class="amount">Amount</th></tr>
+<tr><td>Base salary</td><td class="amount">{{rupiah .Pay.BaseSalary}}</td></tr>
+{{range .Pay.Allowances}}<tr><td>{{.Name}}</td><td class="amount">{{rupiah .Amount}}</td></tr>
+{{end}}<tr class="total"><td>Gross pay</td><td class="amount">{{rupiah .Pay.GrossPay}}</td></tr>
+</table>
+<table>
+<tr><th>Deductions</th><th class="amount">Amount</th></tr>
+{{range .Pay.Deductions}}<tr><td>{{.Name}}</td><td class="amount">{{rupiah .Amount}}</td></tr>
+{{end}}<tr><td>Income tax (PPh 21)</td><td class="amount">{{rupiah .Pay.Tax}}</td></tr>
+{{range .Pay.Contributions}}<tr><td>{{.Name}}</td><td class="amount">{{rupiah .Employee}}</td></tr>
+{{end}}<tr class="total"><td>Net pay</td><td class="amount">{{rupiah .Pay.NetPay}}</td></tr>
+</table>
+{{if .Pay.Contributions}}<table>
+<tr><th>Paid by the company</th><th class="amount">Amount</th></tr>
+{{range .Pay.Contributions}}<tr><td>{{.Name}}</td><td class="amount">{{rupiah .Employer}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// responsePayslip writes payslip in format, json or html.
+func responsePayslip(c echo.Context, format string, payslip *model.Payslip) error {
+	if format != "html" {
+		return helper.ResponseSuccessJson(c, "success", payslip)
+	}
+
+	var page bytes.Buffer
+	if err := payslipTemplate.Execute(&page, payslip); err != nil {
+		return err
+	}
+
+	return helper.ResponseHTML(c, page.Bytes())
+}
+
+// rupiah formats amount like "Rp 1.250.000".
+func rupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	digits := strconv.Itoa(amount)
+	grouped := digits[:(len(digits)-1)%3+1]
+	for i := len(grouped); i < len(digits); i += 3 {
+		grouped += "." + digits[i:i+3]
+	}
+
+	return sign + "Rp " + grouped
+}
@@ -0,0 +1,45 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"self-payrol/model"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRupiah(t *testing.T) {
+	assert.Equal(t, "Rp 0", rupiah(0))
+	assert.Equal(t, "Rp 950", rupiah(950))
+	assert.Equal(t, "Rp 1.250.000", rupiah(1250000))
+	assert.Equal(t, "-Rp 120.000", rupiah(-120000))
+}
+
+func TestResponsePayslipHTML(t *testing.T) {
+	pay := model.NewPayBreakdown(10000000, []*model.PayComponent{
+		{Name: "Transport", Type: model.PayComponentAllowance, Amount: 500000},
+	})
+	pay.Withhold(210000)
+	payslip := &model.Payslip{
+		Period:         "2024-10",
+		PeriodStart:    time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:      time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC),
+		EmployeeName:   "Budi <script>",
+		CompanyName:    "Test Company",
+		CompanyAddress: "Cempaka St.",
+		Pay:            *pay,
+	}
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	assert.NoError(t, responsePayslip(c, "html", payslip))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+	assert.Contains(t, rec.Body.String(), "1 October 2024 - 31 October 2024")
+	assert.Contains(t, rec.Body.String(), "Rp 10.290.000")
+	assert.Contains(t, rec.Body.String(), "Budi &lt;script&gt;")
+}
@@ -24,11 +24,17 @@ func NewPositionDelivery(positionUsecase model.PositionUsecase) PositionDelivery
 }
 
 func (p *positionDelivery) Mount(group *echo.Group) {
-	group.GET("", p.FetchPositionHandler)
-	group.POST("", p.StorePositionHandler)
-	group.GET("/:id", p.DetailPositionHandler)
-	group.DELETE("/:id", p.DeletePositionHandler)
-	group.PATCH("/:id", p.EditPositionHandler)
+	manage := Authorize(model.PermissionManagePositions)
+
+	group.GET("", p.FetchPositionHandler, manage)
+	group.POST("", p.StorePositionHandler, manage)
+	group.GET("/:id", p.DetailPositionHandler, manage)
+	group.DELETE("/:id", p.DeletePositionHandler, manage)
+	group.PATCH("/:id", p.EditPositionHandler, manage)
+	group.GET("/:id/salary-history", p.SalaryHistoryHandler, manage)
+	group.GET("/:id/salary-changes", p.PendingSalaryChangesHandler, manage)
+	group.POST("/:id/salary-changes", p.ScheduleSalaryChangeHandler, manage)
+	group.DELETE("/:id/salary-changes/:change_id", p.CancelSalaryChangeHandler, manage)
 }
 
 func (p *positionDelivery) FetchPositionHandler(c echo.Context) error {
@@ -65,7 +71,7 @@ func (p *positionDelivery) StorePositionHandler(c echo.Context) error {
 
 	position, err := p.positionUsecase.StorePosition(ctx, &req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return helper.ResponseErrorJson(c, http.StatusInternalServerError, err)
 	}
 
 	return helper.ResponseSuccessJson(c, "success", position)
@@ -122,8 +128,75 @@ func (p *positionDelivery) EditPositionHandler(c echo.Context) error {
 
 	position, err := p.positionUsecase.EditPosition(ctx, IdInt, &req)
 	if err != nil {
-		return err
+		return helper.ResponseErrorJson(c, http.StatusInternalServerError, err)
 	}
 
 	return helper.ResponseSuccessJson(c, "Success edit", position)
 }
+
+func (p *positionDelivery) SalaryHistoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	history, err := p.positionUsecase.SalaryHistory(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", history)
+}
+
+func (p *positionDelivery) PendingSalaryChangesHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	changes, err := p.positionUsecase.PendingSalaryChanges(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", changes)
+}
+
+func (p *positionDelivery) ScheduleSalaryChangeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.SalaryChangeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	change, err := p.positionUsecase.ScheduleSalaryChange(ctx, IdInt, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", change)
+}
+
+func (p *positionDelivery) CancelSalaryChangeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+	changeID, _ := strconv.Atoi(c.Param("change_id"))
+
+	if err := p.positionUsecase.CancelSalaryChange(ctx, IdInt, changeID); err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", "")
+}
@@ -1,10 +1,13 @@
 package delivery
 
 import (
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/labstack/echo/v4"
 	"self-payrol/helper"
 	"self-payrol/model"
-	"strconv"
+	"self-payrol/request"
 )
 
 type transactionDelivery struct {
@@ -20,22 +23,53 @@ func NewTransactionDelivery(transactionUsecase model.TransactionUsecase) Transac
 }
 
 func (p *transactionDelivery) Mount(group *echo.Group) {
-	group.GET("", p.FetchTransactionHandler)
+	group.GET("", p.FetchTransactionHandler, Authorize(model.PermissionReadTransactions))
+	group.POST("/:id/reverse", p.ReverseTransactionHandler, Authorize(model.PermissionReverseTransaction))
 }
 
 func (p *transactionDelivery) FetchTransactionHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	limit := c.QueryParam("limit")
-	offset := c.QueryParam("offset")
+	var filter request.TransactionFilter
+
+	if err := c.Bind(&filter); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := filter.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	transactions, meta, i, err := p.transactionUsecase.Fetch(ctx, filter)
+	if err != nil {
+		return helper.ResponseErrorJson(c, i, err)
+	}
+
+	return helper.ResponseSuccessWithMetaJson(c, "success", transactions, meta)
+}
+
+func (p *transactionDelivery) ReverseTransactionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.ReversalRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
 
-	limitInt, _ := strconv.Atoi(limit)
-	offsetInt, _ := strconv.Atoi(offset)
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
 
-	transactions, i, err := p.transactionUsecase.Fetch(ctx, limitInt, offsetInt)
+	transaction, i, err := p.transactionUsecase.Reverse(ctx, IdInt, req)
 	if err != nil {
 		return helper.ResponseErrorJson(c, i, err)
 	}
 
-	return helper.ResponseSuccessJson(c, "success", transactions)
+	return helper.ResponseSuccessJson(c, "success", transaction)
 }
@@ -1,6 +1,7 @@
 package delivery
 
 import (
+	"encoding/json"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -11,24 +12,42 @@ import (
 )
 
 type userDelivery struct {
-	userUsecase model.UserUsecase
+	userUsecase        model.UserUsecase
+	idempotencyUsecase model.IdempotencyUsecase
+	auth               echo.MiddlewareFunc
 }
 
 type UserDelivery interface {
 	Mount(group *echo.Group)
 }
 
-func NewUserDelivery(userUsecase model.UserUsecase) UserDelivery {
-	return &userDelivery{userUsecase: userUsecase}
+func NewUserDelivery(userUsecase model.UserUsecase, idempotencyUsecase model.IdempotencyUsecase,
+	auth echo.MiddlewareFunc) UserDelivery {
+	return &userDelivery{userUsecase: userUsecase, idempotencyUsecase: idempotencyUsecase, auth: auth}
 }
 
+// Mount registers the employee routes. Withdraw is authorised by the employee
+// secret id in its body, every other route needs an access token. Employees
+// may only read their own profile and withdrawals.
 func (p *userDelivery) Mount(group *echo.Group) {
-	group.GET("", p.FetchUserHandler)
-	group.POST("", p.StoreUserHandler)
-	group.GET("/:id", p.DetailUserHandler)
-	group.DELETE("/:id", p.DeleteUserHandler)
-	group.PATCH("/:id", p.EditUserHandler)
-	group.POST("/withdraw", p.WithdrawHandler)
+	manage := Authorize(model.PermissionManageEmployees)
+	readOwn := AuthorizeOwner(model.PermissionManageEmployees)
+
+	group.GET("", p.FetchUserHandler, p.auth, manage)
+	group.POST("", p.StoreUserHandler, p.auth, manage)
+	group.GET("/:id", p.DetailUserHandler, p.auth, readOwn)
+	group.DELETE("/:id", p.DeleteUserHandler, p.auth, manage)
+	group.PATCH("/:id", p.EditUserHandler, p.auth, manage)
+	group.POST("/withdraw", p.WithdrawHandler, Idempotency(p.idempotencyUsecase, withdrawPrincipal))
+	group.GET("/:id/withdrawals", p.FetchWithdrawalHandler, p.auth, readOwn)
+	group.POST("/:id/secret/reset", p.ResetSecretHandler, p.auth, manage)
+	group.POST("/:id/unlock", p.UnlockUserHandler, p.auth, manage)
+	group.PUT("/:id/base-salary", p.SetBaseSalaryHandler, p.auth, manage)
+	group.GET("/:id/pay-components", p.FetchPayComponentHandler, p.auth, readOwn)
+	group.POST("/:id/pay-components", p.StorePayComponentHandler, p.auth, manage)
+	group.DELETE("/:id/pay-components/:component_id", p.DeletePayComponentHandler, p.auth, manage)
+	group.GET("/:id/tax-summary/:year", p.TaxSummaryHandler, p.auth, readOwn)
+	group.GET("/:id/payslips/:period", p.PayslipHandler, p.auth, readOwn)
 }
 
 func (p *userDelivery) FetchUserHandler(c echo.Context) error {
@@ -59,7 +78,7 @@ func (p *userDelivery) StoreUserHandler(c echo.Context) error {
 
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := req.ValidateCreate(); err != nil {
 		errVal := err.(validation.Errors)
 		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
 	}
@@ -114,7 +133,7 @@ func (p *userDelivery) EditUserHandler(c echo.Context) error {
 
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := req.ValidateEdit(); err != nil {
 		errVal := err.(validation.Errors)
 		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
 	}
@@ -130,6 +149,17 @@ func (p *userDelivery) EditUserHandler(c echo.Context) error {
 	return helper.ResponseSuccessJson(c, "Success edit", user)
 }
 
+// withdrawPrincipal names the employee withdrawing by the id in body, as
+// withdrawals are authorised with their secret id instead of an access token.
+func withdrawPrincipal(c echo.Context, body []byte) string {
+	var req request.WithdrawRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+
+	return "employee:" + strconv.Itoa(req.ID)
+}
+
 func (p *userDelivery) WithdrawHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 	var req request.WithdrawRequest
@@ -143,11 +173,189 @@ func (p *userDelivery) WithdrawHandler(c echo.Context) error {
 		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
 	}
 
-	err := p.userUsecase.WithdrawSalary(ctx, &req)
+	req.IP = c.RealIP()
+
+	pay, err := p.userUsecase.WithdrawSalary(ctx, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "Success withdraw salary", pay)
+
+}
+
+func (p *userDelivery) FetchWithdrawalHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	limit := c.QueryParam("limit")
+	offset := c.QueryParam("offset")
+
+	IdInt, _ := strconv.Atoi(id)
+	limitInt, _ := strconv.Atoi(limit)
+	offsetInt, _ := strconv.Atoi(offset)
+
+	withdrawals, err := p.userUsecase.FetchWithdrawals(ctx, IdInt, limitInt, offsetInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", withdrawals)
+}
+
+func (p *userDelivery) ResetSecretHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.SecretResetRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	err := p.userUsecase.ResetSecret(ctx, IdInt, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "Success reset secret id", "")
+}
+
+func (p *userDelivery) UnlockUserHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	user, err := p.userUsecase.Unlock(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "Success unlock user", user)
+}
+
+func (p *userDelivery) SetBaseSalaryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.BaseSalaryRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	user, err := p.userUsecase.SetBaseSalary(ctx, IdInt, &req)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", user)
+}
+
+func (p *userDelivery) FetchPayComponentHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	components, err := p.userUsecase.FetchPayComponents(ctx, IdInt)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", components)
+}
+
+func (p *userDelivery) StorePayComponentHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.PayComponentRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	component, err := p.userUsecase.AddPayComponent(ctx, IdInt, &req)
 	if err != nil {
 		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
 	}
 
-	return helper.ResponseSuccessJson(c, "Success withdraw salary", "")
+	return helper.ResponseSuccessJson(c, "success", component)
+}
+
+func (p *userDelivery) DeletePayComponentHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+	componentID, _ := strconv.Atoi(c.Param("component_id"))
+
+	if err := p.userUsecase.RemovePayComponent(ctx, IdInt, componentID); err != nil {
+		return helper.ResponseErrorJson(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", "")
+}
+
+func (p *userDelivery) TaxSummaryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+	year, _ := strconv.Atoi(c.Param("year"))
+
+	summary, err := p.userUsecase.TaxSummary(ctx, IdInt, year)
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
+
+	return helper.ResponseSuccessJson(c, "success", summary)
+}
+
+func (p *userDelivery) PayslipHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req request.PayslipRequest
+
+	if err := c.Bind(&req); err != nil {
+		return helper.ResponseValidationErrorJson(c, "Error binding struct", err.Error())
+	}
+
+	if err := req.Validate(); err != nil {
+		errVal := err.(validation.Errors)
+		return helper.ResponseValidationErrorJson(c, "Error validation", errVal)
+	}
+
+	id := c.Param("id")
+	IdInt, _ := strconv.Atoi(id)
+
+	payslip, err := p.userUsecase.Payslip(ctx, IdInt, c.Param("period"))
+	if err != nil {
+		return helper.ResponseErrorJson(c, http.StatusBadRequest, err)
+	}
 
+	return responsePayslip(c, req.Format, payslip)
 }
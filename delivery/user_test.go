@@ -0,0 +1,66 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"self-payrol/model"
+	"self-payrol/request"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// editUsecase edits every employee it is asked to.
+type editUsecase struct {
+	model.UserUsecase
+}
+
+func (u *editUsecase) EditUser(ctx context.Context, id int, req *request.UserRequest) (*model.User, error) {
+	return &model.User{ID: id, Name: req.Name}, nil
+}
+
+func TestEditUserHandler(t *testing.T) {
+	auth := Authenticated(&tokenUsecase{claims: map[string]*model.Claims{
+		"hr": {Username: "hr", Role: model.RoleHR},
+	}})
+	e := echo.New()
+	NewUserDelivery(&editUsecase{}, nil, auth).Mount(e.Group("/employee"))
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "should edit employee",
+			body:           `{"name":"user","email":"x@company.com","phone":"0812","address":"Cempaka St.","position_id":1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Success edit",
+		},
+		{
+			name: "should refuse to edit secret id",
+			body: `{"name":"user","email":"x@company.com","phone":"0812","address":"Cempaka St.","position_id":1,` +
+				`"secret_id":"new-secret"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "/employee/:id/secret/reset",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/employee/1", strings.NewReader(test.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer hr")
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		})
+	}
+}
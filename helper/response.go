@@ -1,8 +1,13 @@
 package helper
 
 import (
-	"github.com/labstack/echo/v4"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
+	"self-payrol/model"
+
+	"github.com/labstack/echo/v4"
 )
 
 type (
@@ -10,12 +15,14 @@ type (
 		Success bool        `json:"success"`
 		Message string      `json:"message"`
 		Data    interface{} `json:"data"`
+		Meta    interface{} `json:"meta,omitempty"`
 	}
 
 	errorJson struct {
 		Success bool        `json:"success"`
 		Message string      `json:"message"`
 		Error   interface{} `json:"error"`
+		Code    string      `json:"code,omitempty"`
 	}
 
 	successDeleteJson struct {
@@ -44,6 +51,22 @@ func ResponseSuccessJson(c echo.Context, message string, data interface{}) error
 	return c.JSON(http.StatusOK, res)
 }
 
+func ResponseSuccessWithMetaJson(c echo.Context, message string, data interface{}, meta interface{}) error {
+
+	if message == "" {
+		message = "success"
+	}
+
+	res := successJson{
+		Message: message,
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
 func ResponseValidationErrorJson(c echo.Context, message string, detail interface{}) error {
 	res := errorJson{
 		Message: message,
@@ -54,11 +77,34 @@ func ResponseValidationErrorJson(c echo.Context, message string, detail interfac
 	return c.JSON(http.StatusBadRequest, res)
 }
 
+// ResponseErrorJson writes err with the given status code. A model.DomainError
+// overrides the status with its own and adds its machine-readable code.
 func ResponseErrorJson(c echo.Context, code int, err error) error {
 	res := errorJson{
 		Error: err.Error(),
 	}
+
+	var domainErr *model.DomainError
+	if errors.As(err, &domainErr) {
+		code = domainErr.Status
+		res.Code = domainErr.Code
+	}
+
 	c.JSON(code, res)
 
 	return err
 }
+
+// ResponseCSV writes records as a CSV attachment named filename.
+func ResponseCSV(c echo.Context, filename string, records [][]string) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	return csv.NewWriter(c.Response()).WriteAll(records)
+}
+
+// ResponseHTML writes page as an HTML document.
+func ResponseHTML(c echo.Context, page []byte) error {
+	return c.HTMLBlob(http.StatusOK, page)
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"context"
+	"self-payrol/request"
+	"self-payrol/response"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type claimsCtx struct{}
+
+type (
+	Admin struct {
+		ID        int       `json:"id"`
+		Username  string    `json:"username" gorm:"uniqueIndex"`
+		Password  string    `json:"-"`
+		Name      string    `json:"name"`
+		Role      string    `json:"role" gorm:"default:admin"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// Claims are carried by the access token issued on login. The subject
+	// is the admin id, or the user id on tokens issued to employees, which
+	// also carry it in UserID. Requests made with an API key get claims
+	// without a role, granting only the key's Scopes.
+	Claims struct {
+		Username string       `json:"username"`
+		Role     string       `json:"role"`
+		UserID   int          `json:"user_id,omitempty"`
+		Scopes   []Permission `json:"scopes,omitempty"`
+		jwt.StandardClaims
+	}
+
+	AdminRepository interface {
+		Create(ctx context.Context, admin *Admin) (*Admin, error)
+		FindByUsername(ctx context.Context, username string) (*Admin, error)
+		Count(ctx context.Context) (int64, error)
+	}
+
+	AdminUsecase interface {
+		Login(ctx context.Context, req *request.LoginRequest) (*response.Token, error)
+		EmployeeLogin(ctx context.Context, req *request.EmployeeLoginRequest) (*response.Token, error)
+		Authenticate(ctx context.Context, token string) (*Claims, error)
+		StoreAdmin(ctx context.Context, req *request.AdminRequest) (*Admin, error)
+		EnsureAdmin(ctx context.Context, username, password string) error
+	}
+)
+
+// SetPassword stores the bcrypt hash of password.
+func (a *Admin) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	a.Password = string(hash)
+
+	return nil
+}
+
+// CheckPassword reports whether password matches the stored hash.
+func (a *Admin) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(a.Password), []byte(password)) == nil
+}
+
+// Can reports whether the role or scopes of the claims grant permission.
+func (c *Claims) Can(permission Permission) bool {
+	for _, scope := range c.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+
+	return RoleCan(c.Role, permission)
+}
+
+// ContextWithClaims returns a copy of ctx carrying the access token claims of
+// the request.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsCtx{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored by ContextWithClaims, or nil when
+// the request carried no access token.
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsCtx{}).(*Claims)
+	return claims
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"self-payrol/request"
+	"time"
+)
+
+type (
+	// ApiKey lets another system call the API without a login. Only the
+	// SHA-256 hash of the key is stored, Prefix is kept to tell keys apart.
+	ApiKey struct {
+		ID         int          `json:"id"`
+		Name       string       `json:"name"`
+		Prefix     string       `json:"prefix"`
+		KeyHash    string       `json:"-" gorm:"uniqueIndex"`
+		Scopes     []Permission `json:"scopes" gorm:"serializer:json"`
+		LastUsedAt *time.Time   `json:"last_used_at"`
+		RevokedAt  *time.Time   `json:"revoked_at"`
+		CreatedAt  time.Time    `json:"created_at"`
+		UpdatedAt  time.Time    `json:"updated_at"`
+	}
+
+	// IssuedApiKey is returned once when a key is created, it is the only
+	// time the key itself can be read.
+	IssuedApiKey struct {
+		*ApiKey
+		Key string `json:"key"`
+	}
+
+	ApiKeyRepository interface {
+		Create(ctx context.Context, key *ApiKey) (*ApiKey, error)
+		FindByHash(ctx context.Context, hash string) (*ApiKey, error)
+		Fetch(ctx context.Context, limit, offset int) ([]*ApiKey, error)
+		Revoke(ctx context.Context, id int, revokedAt time.Time) error
+		Touch(ctx context.Context, id int, usedAt time.Time) error
+	}
+
+	ApiKeyUsecase interface {
+		Create(ctx context.Context, req *request.ApiKeyRequest) (*IssuedApiKey, error)
+		Fetch(ctx context.Context, limit, offset int) ([]*ApiKey, error)
+		Revoke(ctx context.Context, id int) error
+		Authenticate(ctx context.Context, key string) (*Claims, error)
+	}
+)
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"self-payrol/request"
+	"self-payrol/response"
+	"time"
+)
+
+type requestInfoCtx struct{}
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	// AuditActorSystem is the actor of changes made by background jobs.
+	AuditActorSystem = "system"
+
+	AuditEntityPosition     = "position"
+	AuditEntityUser         = "user"
+	AuditEntityCompany      = "company"
+	AuditEntitySalaryChange = "salary_change"
+	AuditEntityPayComponent = "pay_component"
+	AuditEntityPayrollRun   = "payroll_run"
+)
+
+type (
+	// AuditLog records who changed an entity and how. Before and After only
+	// hold the fields that changed, Before is empty on a create and After is
+	// empty on a delete.
+	AuditLog struct {
+		ID         int                    `json:"id"`
+		Actor      string                 `json:"actor" gorm:"index"`
+		ActorName  string                 `json:"actor_name"`
+		Action     string                 `json:"action"`
+		EntityType string                 `json:"entity_type" gorm:"index:idx_audit_logs_entity"`
+		EntityID   int                    `json:"entity_id" gorm:"index:idx_audit_logs_entity"`
+		Before     map[string]interface{} `json:"before" gorm:"serializer:json"`
+		After      map[string]interface{} `json:"after" gorm:"serializer:json"`
+		RequestID  string                 `json:"request_id"`
+		IP         string                 `json:"ip"`
+		CreatedAt  time.Time              `json:"created_at" gorm:"index"`
+	}
+
+	// RequestInfo describes the HTTP request a change is made in.
+	RequestInfo struct {
+		ID string
+		IP string
+	}
+
+	AuditLogRepository interface {
+		Create(ctx context.Context, log *AuditLog) (*AuditLog, error)
+		Fetch(ctx context.Context, filter request.AuditLogFilter) ([]*AuditLog, error)
+		Count(ctx context.Context, filter request.AuditLogFilter) (int64, error)
+	}
+
+	AuditLogUsecase interface {
+		Fetch(ctx context.Context, filter request.AuditLogFilter) ([]*AuditLog, *response.Meta, int, error)
+	}
+)
+
+// NewAuditLog describes the change of the entity from before to after, made
+// in the request carried by ctx. Either side may be nil. Only the fields whose
+// JSON value differs are kept, timestamps maintained by the database are left
+// out.
+func NewAuditLog(ctx context.Context, action, entityType string, entityID int, before, after interface{}) (*AuditLog, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	for field, value := range beforeFields {
+		if other, ok := afterFields[field]; ok && reflect.DeepEqual(value, other) {
+			delete(beforeFields, field)
+			delete(afterFields, field)
+		}
+	}
+
+	log := &AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeFields,
+		After:      afterFields,
+	}
+
+	log.Actor = ActorFromContext(ctx)
+	if claims := ClaimsFromContext(ctx); claims != nil {
+		log.ActorName = claims.Username
+	}
+
+	if info := RequestInfoFromContext(ctx); info != nil {
+		log.RequestID = info.ID
+		log.IP = info.IP
+	}
+
+	return log, nil
+}
+
+// auditFields flattens entity into its JSON fields.
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	if value := reflect.ValueOf(entity); !value.IsValid() || value.Kind() == reflect.Ptr && value.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	delete(fields, "created_at")
+	delete(fields, "updated_at")
+
+	return fields, nil
+}
+
+// Actor identifies who the claims were issued to, such as "admin:1",
+// "employee:7" or "api_key:3".
+func (c *Claims) Actor() string {
+	switch c.Role {
+	case "":
+		return c.Subject
+	case RoleEmployee:
+		return "employee:" + c.Subject
+	default:
+		return "admin:" + c.Subject
+	}
+}
+
+// ActorFromContext identifies who makes the request carried by ctx, like
+// Claims.Actor, or is AuditActorSystem outside of a request.
+func ActorFromContext(ctx context.Context) string {
+	if claims := ClaimsFromContext(ctx); claims != nil {
+		return claims.Actor()
+	}
+
+	return AuditActorSystem
+}
+
+// ContextWithRequestInfo returns a copy of ctx carrying info, which is then
+// written on the audit logs created while handling the request.
+func ContextWithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoCtx{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoCtx{}).(*RequestInfo)
+	return info
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuditLog(t *testing.T) {
+	ctx := ContextWithClaims(context.Background(), &Claims{
+		Username:       "hr",
+		Role:           RoleHR,
+		StandardClaims: jwt.StandardClaims{Subject: "2"},
+	})
+	ctx = ContextWithRequestInfo(ctx, &RequestInfo{ID: "req-1", IP: "10.0.0.1"})
+	position := &Position{ID: 1, Name: "Staff", Salary: 100000, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	tests := []struct {
+		name           string
+		action         string
+		before         interface{}
+		after          interface{}
+		expectedBefore map[string]interface{}
+		expectedAfter  map[string]interface{}
+	}{
+		{
+			name:           "should keep every field on create",
+			action:         AuditActionCreate,
+			after:          position,
+			expectedBefore: map[string]interface{}{},
+			expectedAfter:  map[string]interface{}{"id": float64(1), "name": "Staff", "salary": float64(100000)},
+		},
+		{
+			name:   "should keep the changed fields only on update",
+			action: AuditActionUpdate,
+			before: position,
+			after: &Position{ID: 1, Name: "Staff", Salary: 150000, CreatedAt: position.CreatedAt,
+				UpdatedAt: time.Now().Add(time.Hour)},
+			expectedBefore: map[string]interface{}{"salary": float64(100000)},
+			expectedAfter:  map[string]interface{}{"salary": float64(150000)},
+		},
+		{
+			name:           "should keep every field on delete",
+			action:         AuditActionDelete,
+			before:         position,
+			after:          (*Position)(nil),
+			expectedBefore: map[string]interface{}{"id": float64(1), "name": "Staff", "salary": float64(100000)},
+			expectedAfter:  map[string]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log, err := NewAuditLog(ctx, test.action, AuditEntityPosition, 1, test.before, test.after)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedBefore, log.Before)
+			assert.Equal(t, test.expectedAfter, log.After)
+			assert.Equal(t, "admin:2", log.Actor)
+			assert.Equal(t, "hr", log.ActorName)
+			assert.Equal(t, "req-1", log.RequestID)
+			assert.Equal(t, "10.0.0.1", log.IP)
+		})
+	}
+}
+
+func TestClaimsActor(t *testing.T) {
+	assert.Equal(t, "admin:1", (&Claims{Role: RoleAdmin, StandardClaims: jwt.StandardClaims{Subject: "1"}}).Actor())
+	assert.Equal(t, "employee:7", (&Claims{Role: RoleEmployee, StandardClaims: jwt.StandardClaims{Subject: "7"}}).Actor())
+	assert.Equal(t, "api_key:3", (&Claims{StandardClaims: jwt.StandardClaims{Subject: "api_key:3"}}).Actor())
+}
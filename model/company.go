@@ -8,24 +8,38 @@ import (
 
 type (
 	Company struct {
-		ID        int       `json:"id"`
-		Name      string    `json:"name"`
-		Address   string    `json:"address"`
-		Balance   int       `json:"balance"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
+		ID             int       `json:"id"`
+		Name           string    `json:"name"`
+		Address        string    `json:"address"`
+		Balance        int       `json:"balance"`
+		OverdraftLimit int       `json:"overdraft_limit"`
+		PayPeriod      string    `json:"pay_period" gorm:"default:monthly"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
 	}
 
 	CompanyRepository interface {
 		Get(ctx context.Context) (*Company, error)
-		CreateOrUpdate(ctx context.Context, Company *Company) (*Company, error)
-		AddBalance(ctx context.Context, balance int) (*Company, error)
-		DebitBalance(ctx context.Context, amount int, note string) error
+		GetForUpdate(ctx context.Context) (*Company, error)
+		Create(ctx context.Context, company *Company) (*Company, error)
+		UpdateProfile(ctx context.Context, id int, company *Company) (*Company, error)
+		AddBalance(ctx context.Context, transaction *Transaction) (*Company, error)
+		DebitBalance(ctx context.Context, transaction *Transaction) error
 	}
 
 	CompanyUsecase interface {
 		GetCompanyInfo(ctx context.Context) (*Company, int, error)
 		CreateOrUpdateCompany(ctx context.Context, req request.CompanyRequest) (*Company, int, error)
 		TopupBalance(ctx context.Context, req request.TopupCompanyBalance) (*Company, int, error)
+		GetStatement(ctx context.Context, req request.StatementRequest) (*Statement, int, error)
+		Reconcile(ctx context.Context) (*Reconciliation, int, error)
+		AdjustToBalance(ctx context.Context, req request.AdjustmentRequest) (*Reconciliation, int, error)
+		AdjustBalance(ctx context.Context, req request.BalanceAdjustmentRequest) (*Company, int, error)
 	}
 )
+
+// CanDebit reports whether amount can be taken from the balance. The balance
+// may go below zero by at most OverdraftLimit.
+func (c *Company) CanDebit(amount int) bool {
+	return c.Balance-amount >= -c.OverdraftLimit
+}
@@ -0,0 +1,81 @@
+package model
+
+const (
+	ContributionBPJSKesehatan = "bpjs_kesehatan"
+	ContributionBPJSJHT       = "bpjs_jht"
+	ContributionBPJSJP        = "bpjs_jp"
+)
+
+type (
+	// ContributionRule is a social security program salaries contribute to.
+	// Rates are in hundredths of a percent of the wage, which is capped at
+	// WageCap a month. A zero WageCap leaves the wage uncapped.
+	ContributionRule struct {
+		Name         string
+		EmployeeRate int
+		EmployerRate int
+		WageCap      int
+	}
+
+	// Contribution is what one salary contributes to a program. The employee
+	// part is taken off the net pay, the employer part is paid on top.
+	Contribution struct {
+		Name     string `json:"name"`
+		Wage     int    `json:"wage"`
+		Employee int    `json:"employee"`
+		Employer int    `json:"employer"`
+	}
+)
+
+// Contribute works out the contribution of wage, the gross pay of a salary
+// paid at frequency. Monthly caps are scaled to the pay period.
+func (r ContributionRule) Contribute(wage int, frequency string) Contribution {
+	if wageCap := r.WageCap * 12 / PeriodsPerYear(frequency); r.WageCap > 0 && wage > wageCap {
+		wage = wageCap
+	}
+
+	return Contribution{
+		Name:     r.Name,
+		Wage:     wage,
+		Employee: wage * r.EmployeeRate / 10000,
+		Employer: wage * r.EmployerRate / 10000,
+	}
+}
+
+// TaxableBenefits adds up the part of contributions taxed as income of the
+// employee: the BPJS Kesehatan premium the company pays for them.
+func TaxableBenefits(contributions []Contribution) int {
+	benefits := 0
+	for _, contribution := range contributions {
+		if contribution.Name == ContributionBPJSKesehatan {
+			benefits += contribution.Employer
+		}
+	}
+
+	return benefits
+}
+
+// DeductibleContributions adds up the part of contributions deducted from the
+// income of the employee before tax: their own JHT and JP contributions.
+func DeductibleContributions(contributions []Contribution) int {
+	deductible := 0
+	for _, contribution := range contributions {
+		if contribution.Name == ContributionBPJSJHT || contribution.Name == ContributionBPJSJP {
+			deductible += contribution.Employee
+		}
+	}
+
+	return deductible
+}
+
+// PeriodsPerYear returns how many salaries are paid a year at frequency.
+func PeriodsPerYear(frequency string) int {
+	switch frequency {
+	case PayPeriodWeekly:
+		return 52
+	case PayPeriodBiweekly:
+		return 26
+	default:
+		return 12
+	}
+}
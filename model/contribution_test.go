@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContributionRuleContribute(t *testing.T) {
+	kesehatan := ContributionRule{Name: ContributionBPJSKesehatan, EmployeeRate: 100, EmployerRate: 400, WageCap: 12000000}
+	jht := ContributionRule{Name: ContributionBPJSJHT, EmployeeRate: 200, EmployerRate: 370}
+	tests := []struct {
+		name      string
+		rule      ContributionRule
+		wage      int
+		frequency string
+		expected  Contribution
+	}{
+		{
+			name:      "should contribute on wages below the cap",
+			rule:      kesehatan,
+			wage:      10000000,
+			frequency: PayPeriodMonthly,
+			expected:  Contribution{Name: ContributionBPJSKesehatan, Wage: 10000000, Employee: 100000, Employer: 400000},
+		},
+		{
+			name:      "should cap the wage",
+			rule:      kesehatan,
+			wage:      20000000,
+			frequency: PayPeriodMonthly,
+			expected:  Contribution{Name: ContributionBPJSKesehatan, Wage: 12000000, Employee: 120000, Employer: 480000},
+		},
+		{
+			name:      "should scale the cap to weekly salaries",
+			rule:      kesehatan,
+			wage:      5000000,
+			frequency: PayPeriodWeekly,
+			expected:  Contribution{Name: ContributionBPJSKesehatan, Wage: 2769230, Employee: 27692, Employer: 110769},
+		},
+		{
+			name:      "should not cap without a wage cap",
+			rule:      jht,
+			wage:      100000000,
+			frequency: PayPeriodMonthly,
+			expected:  Contribution{Name: ContributionBPJSJHT, Wage: 100000000, Employee: 2000000, Employer: 3700000},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.rule.Contribute(test.wage, test.frequency))
+		})
+	}
+}
+
+func TestContributionsTaxTreatment(t *testing.T) {
+	contributions := []Contribution{
+		{Name: ContributionBPJSKesehatan, Wage: 10000000, Employee: 100000, Employer: 400000},
+		{Name: ContributionBPJSJHT, Wage: 10000000, Employee: 200000, Employer: 370000},
+		{Name: ContributionBPJSJP, Wage: 10000000, Employee: 100000, Employer: 200000},
+	}
+
+	// only the BPJS Kesehatan premium of the company is taxed as income
+	assert.Equal(t, 400000, TaxableBenefits(contributions))
+	// only the JHT and JP contributions of the employee are deducted
+	assert.Equal(t, 300000, DeductibleContributions(contributions))
+}
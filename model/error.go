@@ -0,0 +1,141 @@
+package model
+
+import "net/http"
+
+// DomainError is a business rule violation that is reported to API clients
+// with a machine-readable code next to the message.
+type DomainError struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+var ErrInsufficientBalance = &DomainError{
+	Code:    "insufficient_balance",
+	Message: "company balance is not enough for this debit",
+	Status:  http.StatusConflict,
+}
+
+var ErrLedgerBalanced = &DomainError{
+	Code:    "ledger_balanced",
+	Message: "company balance already matches the ledger",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrFutureEffectiveDate = &DomainError{
+	Code:    "future_effective_date",
+	Message: "the first salary of a position cannot take effect in the future",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrPastEffectiveDate = &DomainError{
+	Code:    "past_effective_date",
+	Message: "a scheduled salary change must take effect after today",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrSalaryChangeNotPending = &DomainError{
+	Code:    "salary_change_not_pending",
+	Message: "salary change has already taken effect or was cancelled",
+	Status:  http.StatusConflict,
+}
+
+var ErrNoNetPay = &DomainError{
+	Code:    "no_net_pay",
+	Message: "deductions leave no salary to withdraw",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrBalanceReadOnly = &DomainError{
+	Code:    "balance_read_only",
+	Message: "company balance can only be changed through a top-up or a balance adjustment",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrIdempotencyKeyReused = &DomainError{
+	Code:    "idempotency_key_reused",
+	Message: "idempotency key has already been used with a different request",
+	Status:  http.StatusConflict,
+}
+
+var ErrIdempotencyInProgress = &DomainError{
+	Code:    "idempotency_key_in_progress",
+	Message: "a request with this idempotency key is still being processed",
+	Status:  http.StatusConflict,
+}
+
+var ErrAlreadyWithdrawn = &DomainError{
+	Code:    "already_withdrawn",
+	Message: "salary for this pay period has already been withdrawn",
+	Status:  http.StatusConflict,
+}
+
+var ErrAlreadyReversed = &DomainError{
+	Code:    "already_reversed",
+	Message: "transaction has already been reversed",
+	Status:  http.StatusConflict,
+}
+
+var ErrNotReversible = &DomainError{
+	Code:    "not_reversible",
+	Message: "only completed transactions that moved the balance and are not reversals can be reversed, tax and contributions only with their salary",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrFuturePayPeriod = &DomainError{
+	Code:    "future_pay_period",
+	Message: "a payroll run cannot pay a pay period that has not started",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrPayrollRunEmpty = &DomainError{
+	Code:    "payroll_run_empty",
+	Message: "there is no employee left to pay for this pay period",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrPayrollRunStatus = &DomainError{
+	Code:    "payroll_run_status",
+	Message: "payroll run is not in a status that allows this",
+	Status:  http.StatusConflict,
+}
+
+var ErrEmployeeLocked = &DomainError{
+	Code:    "employee_locked",
+	Message: "withdrawals are locked after too many invalid secret ids, try again later",
+	Status:  http.StatusLocked,
+}
+
+var ErrTooManyAttempts = &DomainError{
+	Code:    "too_many_attempts",
+	Message: "too many invalid secret ids from this client, try again later",
+	Status:  http.StatusTooManyRequests,
+}
+
+var ErrInvalidScope = &DomainError{
+	Code:    "invalid_scope",
+	Message: "scope cannot be granted to an API key",
+	Status:  http.StatusUnprocessableEntity,
+}
+
+var ErrInvalidCredentials = &DomainError{
+	Code:    "invalid_credentials",
+	Message: "username or password is not valid",
+	Status:  http.StatusUnauthorized,
+}
+
+var ErrUnauthorized = &DomainError{
+	Code:    "unauthorized",
+	Message: "missing or invalid access token",
+	Status:  http.StatusUnauthorized,
+}
+
+var ErrForbidden = &DomainError{
+	Code:    "forbidden",
+	Message: "your role is not allowed to do this",
+	Status:  http.StatusForbidden,
+}
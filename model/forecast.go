@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+type (
+	// Forecast projects the payroll of the coming pay periods against the
+	// company balance. Shortfall is what the balance, overdraft included,
+	// misses to pay every period and RunsOutAt is when the first period it
+	// cannot pay falls due. Both are empty when the balance covers them all.
+	Forecast struct {
+		Balance        int              `json:"balance"`
+		OverdraftLimit int              `json:"overdraft_limit"`
+		Periods        []ForecastPeriod `json:"periods"`
+		TotalCost      int              `json:"total_cost"`
+		Shortfall      int              `json:"shortfall"`
+		RunsOutAt      *time.Time       `json:"runs_out_at"`
+	}
+
+	// ForecastPeriod is what paying the employees of one pay period costs and
+	// the balance left once they are paid.
+	ForecastPeriod struct {
+		PayPeriod
+		DueAt     time.Time `json:"due_at"`
+		Employees int       `json:"employees"`
+		Cost      int       `json:"cost"`
+		Balance   int       `json:"balance"`
+	}
+)
+
+// NewForecast starts the forecast of company from its current balance.
+func NewForecast(company *Company) *Forecast {
+	return &Forecast{
+		Balance:        company.Balance,
+		OverdraftLimit: company.OverdraftLimit,
+		Periods:        []ForecastPeriod{},
+	}
+}
+
+// Add pays cost, the pay of employees for period falling due at dueAt, out of
+// the balance left by the periods added before.
+func (f *Forecast) Add(period PayPeriod, dueAt time.Time, employees, cost int) {
+	f.TotalCost += cost
+	balance := f.Balance - f.TotalCost
+
+	f.Periods = append(f.Periods, ForecastPeriod{
+		PayPeriod: period,
+		DueAt:     dueAt,
+		Employees: employees,
+		Cost:      cost,
+		Balance:   balance,
+	})
+
+	if shortfall := -f.OverdraftLimit - balance; shortfall > 0 {
+		f.Shortfall = shortfall
+
+		if f.RunsOutAt == nil {
+			f.RunsOutAt = &dueAt
+		}
+	}
+}
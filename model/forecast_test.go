@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForecastAdd(t *testing.T) {
+	october := NewPayPeriod(PayPeriodMonthly, time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC))
+	november := NewPayPeriod(PayPeriodMonthly, october.End)
+	tests := []struct {
+		name              string
+		company           *Company
+		expectedBalances  []int
+		expectedShortfall int
+		expectedRunsOutAt *time.Time
+	}{
+		{
+			name:             "should cover every period",
+			company:          &Company{Balance: 300},
+			expectedBalances: []int{200, 0},
+		},
+		{
+			name:              "should run out on the first period it cannot pay",
+			company:           &Company{Balance: 150},
+			expectedBalances:  []int{50, -150},
+			expectedShortfall: 150,
+			expectedRunsOutAt: &november.Start,
+		},
+		{
+			name:              "should pay out of the overdraft",
+			company:           &Company{Balance: 150, OverdraftLimit: 100},
+			expectedBalances:  []int{50, -150},
+			expectedShortfall: 50,
+			expectedRunsOutAt: &november.Start,
+		},
+		{
+			name:              "should run out on the current period",
+			company:           &Company{Balance: 50},
+			expectedBalances:  []int{-50, -250},
+			expectedShortfall: 250,
+			expectedRunsOutAt: &october.Start,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			forecast := NewForecast(test.company)
+			forecast.Add(october, october.Start, 1, 100)
+			forecast.Add(november, november.Start, 2, 200)
+
+			var balances []int
+			for _, period := range forecast.Periods {
+				balances = append(balances, period.Balance)
+			}
+			assert.Equal(t, test.expectedBalances, balances)
+			assert.Equal(t, 300, forecast.TotalCost)
+			assert.Equal(t, test.expectedShortfall, forecast.Shortfall)
+			assert.Equal(t, test.expectedRunsOutAt, forecast.RunsOutAt)
+		})
+	}
+}
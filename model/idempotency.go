@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type idempotencyKeyCtx struct{}
+
+type (
+	// IdempotencyKey remembers the response given to a request carrying an
+	// Idempotency-Key header, so a retry of the same request gets the same
+	// response instead of moving money again. StatusCode stays zero while
+	// the first request is still being handled.
+	IdempotencyKey struct {
+		ID          int       `json:"id"`
+		Key         string    `json:"key" gorm:"uniqueIndex:idx_idempotency_keys_key_scope"`
+		Scope       string    `json:"scope" gorm:"uniqueIndex:idx_idempotency_keys_key_scope"`
+		RequestHash string    `json:"request_hash"`
+		StatusCode  int       `json:"status_code"`
+		Response    []byte    `json:"-"`
+		ExpiresAt   time.Time `json:"expires_at"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	IdempotencyRepository interface {
+		Create(ctx context.Context, key *IdempotencyKey) (*IdempotencyKey, error)
+		Find(ctx context.Context, key, scope string) (*IdempotencyKey, error)
+		Complete(ctx context.Context, id, statusCode int, response []byte) error
+		Delete(ctx context.Context, id int) error
+	}
+
+	IdempotencyUsecase interface {
+		Begin(ctx context.Context, key, scope, requestHash string) (*IdempotencyKey, error)
+		Complete(ctx context.Context, key *IdempotencyKey, statusCode int, response []byte) error
+		Release(ctx context.Context, key *IdempotencyKey) error
+	}
+)
+
+// Completed reports whether a response has been stored for the key.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
+
+// ContextWithIdempotencyKey returns a copy of ctx carrying key, which is then
+// written on the ledger entries created while handling the request.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PayComponentAllowance = "allowance"
+	PayComponentDeduction = "deduction"
+)
+
+type (
+	// PayComponent is added to, or for a deduction taken from, every salary
+	// of the employee next to their base salary.
+	PayComponent struct {
+		ID        int       `json:"id"`
+		UserID    int       `json:"user_id" gorm:"index"`
+		Name      string    `json:"name"`
+		Type      string    `json:"type"`
+		Amount    int       `json:"amount"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	PayLine struct {
+		Name   string `json:"name"`
+		Amount int    `json:"amount"`
+	}
+
+	// PayBreakdown explains how the net pay of one salary adds up. Employee
+	// contributions are taken off the net pay, employer contributions are paid
+	// by the company on top of the gross pay.
+	PayBreakdown struct {
+		BaseSalary            int            `json:"base_salary"`
+		Allowances            []PayLine      `json:"allowances"`
+		Deductions            []PayLine      `json:"deductions"`
+		GrossPay              int            `json:"gross_pay"`
+		TotalDeductions       int            `json:"total_deductions"`
+		Tax                   int            `json:"tax"`
+		Contributions         []Contribution `json:"contributions"`
+		EmployeeContributions int            `json:"employee_contributions"`
+		EmployerContributions int            `json:"employer_contributions"`
+		NetPay                int            `json:"net_pay"`
+	}
+
+	PayComponentRepository interface {
+		Create(ctx context.Context, component *PayComponent) (*PayComponent, error)
+		FetchByUser(ctx context.Context, userID int) ([]*PayComponent, error)
+		Delete(ctx context.Context, userID, id int) error
+	}
+)
+
+// NewPayBreakdown adds the allowances in components to baseSalary for the
+// gross pay and takes the deductions off it for the net pay.
+func NewPayBreakdown(baseSalary int, components []*PayComponent) *PayBreakdown {
+	pay := &PayBreakdown{
+		BaseSalary:    baseSalary,
+		Allowances:    []PayLine{},
+		Deductions:    []PayLine{},
+		Contributions: []Contribution{},
+		GrossPay:      baseSalary,
+	}
+
+	for _, component := range components {
+		line := PayLine{Name: component.Name, Amount: component.Amount}
+
+		if component.Type == PayComponentDeduction {
+			pay.Deductions = append(pay.Deductions, line)
+			pay.TotalDeductions += component.Amount
+			continue
+		}
+
+		pay.Allowances = append(pay.Allowances, line)
+		pay.GrossPay += component.Amount
+	}
+
+	pay.NetPay = pay.GrossPay - pay.TotalDeductions
+
+	return pay
+}
+
+// Withhold takes tax off the net pay.
+func (p *PayBreakdown) Withhold(tax int) {
+	p.Tax += tax
+	p.NetPay -= tax
+}
+
+// Contribute adds the contributions of the gross pay, paid at frequency, to
+// the programs of rules.
+func (p *PayBreakdown) Contribute(rules []ContributionRule, frequency string) {
+	for _, rule := range rules {
+		contribution := rule.Contribute(p.GrossPay, frequency)
+
+		p.Contributions = append(p.Contributions, contribution)
+		p.EmployeeContributions += contribution.Employee
+		p.EmployerContributions += contribution.Employer
+		p.NetPay -= contribution.Employee
+	}
+}
+
+// Cost is what paying the salary debits from the company balance, the net pay
+// plus the tax and the contributions of the employee and of the company.
+func (p *PayBreakdown) Cost() int {
+	return p.NetPay + p.Tax + p.EmployeeContributions + p.EmployerContributions
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"self-payrol/request"
+	"time"
+)
+
+const (
+	PayrollRunDraft     = "draft"
+	PayrollRunApproved  = "approved"
+	PayrollRunExecuted  = "executed"
+	PayrollRunCancelled = "cancelled"
+)
+
+type (
+	// PayrollRun pays every employee the salary of one pay period at once. A
+	// run is drafted with the pay of each employee worked out for review, then
+	// approved and executed, which pays all of them in a single database
+	// transaction. TotalCost is everything the run debits from the company
+	// balance: the net pay, the tax withheld and the contributions of both the
+	// employees and the company.
+	PayrollRun struct {
+		ID          int               `json:"id"`
+		Period      string            `json:"period" gorm:"index"`
+		PeriodStart time.Time         `json:"period_start"`
+		PeriodEnd   time.Time         `json:"period_end"`
+		Status      string            `json:"status" gorm:"index"`
+		TotalNetPay int               `json:"total_net_pay"`
+		TotalCost   int               `json:"total_cost"`
+		CreatedBy   string            `json:"created_by"`
+		ApprovedBy  string            `json:"approved_by"`
+		ApprovedAt  *time.Time        `json:"approved_at"`
+		ExecutedAt  *time.Time        `json:"executed_at"`
+		CancelledAt *time.Time        `json:"cancelled_at"`
+		Items       []*PayrollRunItem `json:"items,omitempty"`
+		CreatedAt   time.Time         `json:"created_at"`
+		UpdatedAt   time.Time         `json:"updated_at"`
+	}
+
+	// PayrollRunItem is the pay of one employee in a payroll run, as it was
+	// worked out when the run was drafted.
+	PayrollRunItem struct {
+		ID           int          `json:"id"`
+		PayrollRunID int          `json:"payroll_run_id" gorm:"index"`
+		UserID       int          `json:"user_id"`
+		EmployeeName string       `json:"employee_name"`
+		Pay          PayBreakdown `json:"pay" gorm:"serializer:json"`
+	}
+
+	PayrollRunRepository interface {
+		Create(ctx context.Context, run *PayrollRun) (*PayrollRun, error)
+		FindByID(ctx context.Context, id int) (*PayrollRun, error)
+		Fetch(ctx context.Context, limit, offset int) ([]*PayrollRun, error)
+		UpdateStatus(ctx context.Context, run *PayrollRun, from string) error
+	}
+
+	PayrollRunUsecase interface {
+		Create(ctx context.Context, req request.PayrollRunRequest) (*PayrollRun, int, error)
+		Fetch(ctx context.Context, limit, offset int) ([]*PayrollRun, int, error)
+		GetByID(ctx context.Context, id int) (*PayrollRun, int, error)
+		Approve(ctx context.Context, id int) (*PayrollRun, int, error)
+		Execute(ctx context.Context, id int) (*PayrollRun, int, error)
+		Cancel(ctx context.Context, id int) (*PayrollRun, int, error)
+		Forecast(ctx context.Context, req request.ForecastRequest) (*Forecast, int, error)
+	}
+)
+
+// Add puts the pay of user in the run.
+func (r *PayrollRun) Add(user *User, pay *PayBreakdown) {
+	r.Items = append(r.Items, &PayrollRunItem{
+		UserID:       user.ID,
+		EmployeeName: user.Name,
+		Pay:          *pay,
+	})
+
+	r.TotalNetPay += pay.NetPay
+	r.TotalCost += pay.Cost()
+}
+
+// PayPeriod is the pay period the run pays.
+func (r *PayrollRun) PayPeriod() PayPeriod {
+	return PayPeriod{Key: r.Period, Start: r.PeriodStart, End: r.PeriodEnd}
+}
+
+// InStatus reports whether the run is in one of statuses.
+func (r *PayrollRun) InStatus(statuses ...string) bool {
+	for _, status := range statuses {
+		if r.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Payslip documents one salary payout. The names of the employee, their
+	// position and the company are copied as they were when it was paid, so
+	// later changes leave it untouched.
+	Payslip struct {
+		ID             int          `json:"id"`
+		UserID         int          `json:"user_id" gorm:"uniqueIndex:idx_payslips_user_period"`
+		Period         string       `json:"period" gorm:"uniqueIndex:idx_payslips_user_period"`
+		PeriodStart    time.Time    `json:"period_start"`
+		PeriodEnd      time.Time    `json:"period_end"`
+		EmployeeName   string       `json:"employee_name"`
+		PositionName   string       `json:"position_name"`
+		CompanyName    string       `json:"company_name"`
+		CompanyAddress string       `json:"company_address"`
+		Pay            PayBreakdown `json:"pay" gorm:"serializer:json"`
+		TransactionID  int          `json:"transaction_id" gorm:"index"`
+		CreatedAt      time.Time    `json:"created_at"`
+	}
+
+	PayslipRepository interface {
+		Create(ctx context.Context, payslip *Payslip) (*Payslip, error)
+		FindByUserAndPeriod(ctx context.Context, userID int, period string) (*Payslip, error)
+		DeleteByTransaction(ctx context.Context, transactionID int) error
+	}
+)
+
+// NewPayslip documents pay, paid to user by company for period with the
+// ledger entry transactionID.
+func NewPayslip(user *User, company *Company, period PayPeriod, pay *PayBreakdown, transactionID int) *Payslip {
+	payslip := &Payslip{
+		UserID:         user.ID,
+		Period:         period.Key,
+		PeriodStart:    period.Start,
+		PeriodEnd:      period.End,
+		EmployeeName:   user.Name,
+		CompanyName:    company.Name,
+		CompanyAddress: company.Address,
+		Pay:            *pay,
+		TransactionID:  transactionID,
+	}
+
+	if user.Position != nil {
+		payslip.PositionName = user.Position.Name
+	}
+
+	return payslip
+}
@@ -29,5 +29,10 @@ type (
 		DestroyPosition(ctx context.Context, id int) error
 		EditPosition(ctx context.Context, id int, req *request.PositionRequest) (*Position, error)
 		StorePosition(ctx context.Context, req *request.PositionRequest) (*Position, error)
+		SalaryHistory(ctx context.Context, id int) ([]*SalaryVersion, error)
+		ScheduleSalaryChange(ctx context.Context, id int, req *request.SalaryChangeRequest) (*SalaryVersion, error)
+		PendingSalaryChanges(ctx context.Context, id int) ([]*SalaryVersion, error)
+		CancelSalaryChange(ctx context.Context, id, changeID int) error
+		ApplySalaryChanges(ctx context.Context) (int, error)
 	}
 )
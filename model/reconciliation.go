@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type (
+	// Reconciliation compares Company.Balance with the balance recomputed from
+	// the completed ledger entries. Discrepancy is the company balance minus
+	// the ledger balance.
+	Reconciliation struct {
+		CompanyBalance int          `json:"company_balance"`
+		LedgerBalance  int          `json:"ledger_balance"`
+		Discrepancy    int          `json:"discrepancy"`
+		Balanced       bool         `json:"balanced"`
+		Adjustment     *Transaction `json:"adjustment,omitempty"`
+		CheckedAt      time.Time    `json:"checked_at"`
+	}
+)
@@ -0,0 +1,82 @@
+package model
+
+// Permission is an action on a group of resources that a role may be granted.
+type Permission string
+
+const (
+	RoleAdmin    = "admin"
+	RoleHR       = "hr"
+	RoleFinance  = "finance"
+	RoleEmployee = "employee"
+
+	PermissionManageAdmins       Permission = "admins:manage"
+	PermissionManageApiKeys      Permission = "api_keys:manage"
+	PermissionManageEmployees    Permission = "employees:manage"
+	PermissionManagePositions    Permission = "positions:manage"
+	PermissionReadCompany        Permission = "company:read"
+	PermissionManageCompany      Permission = "company:manage"
+	PermissionTopup              Permission = "company:topup"
+	PermissionReadTransactions   Permission = "transactions:read"
+	PermissionReverseTransaction Permission = "transactions:reverse"
+	PermissionReadOwnProfile     Permission = "profile:read"
+	PermissionReadAuditLogs      Permission = "audit_logs:read"
+	PermissionManagePayroll      Permission = "payroll:manage"
+)
+
+// ApiKeyScopes lists the permissions an API key may be granted. Managing
+// admins and API keys and the employee self-service stay with logged in users.
+var ApiKeyScopes = []Permission{
+	PermissionManageEmployees,
+	PermissionManagePositions,
+	PermissionReadCompany,
+	PermissionManageCompany,
+	PermissionTopup,
+	PermissionReadTransactions,
+	PermissionReverseTransaction,
+	PermissionReadAuditLogs,
+	PermissionManagePayroll,
+}
+
+// rolePermissions lists what each role may do. The admin role is granted
+// every permission and is not listed.
+var rolePermissions = map[string][]Permission{
+	RoleHR: {
+		PermissionManageEmployees,
+		PermissionManagePositions,
+	},
+	RoleFinance: {
+		PermissionReadCompany,
+		PermissionTopup,
+		PermissionReadTransactions,
+		PermissionManagePayroll,
+	},
+	RoleEmployee: {
+		PermissionReadOwnProfile,
+	},
+}
+
+// ApiKeyScope reports whether scope may be granted to an API key.
+func ApiKeyScope(scope Permission) bool {
+	for _, allowed := range ApiKeyScopes {
+		if allowed == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoleCan reports whether role is granted permission.
+func RoleCan(role string, permission Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
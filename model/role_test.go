@@ -0,0 +1,95 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		permission Permission
+		expected   bool
+	}{
+		{
+			name:       "should grant admin everything",
+			role:       RoleAdmin,
+			permission: PermissionReverseTransaction,
+			expected:   true,
+		},
+		{
+			name:       "should let hr manage employees",
+			role:       RoleHR,
+			permission: PermissionManageEmployees,
+			expected:   true,
+		},
+		{
+			name:       "should let hr manage positions",
+			role:       RoleHR,
+			permission: PermissionManagePositions,
+			expected:   true,
+		},
+		{
+			name:       "should forbid hr to top up",
+			role:       RoleHR,
+			permission: PermissionTopup,
+		},
+		{
+			name:       "should let finance top up",
+			role:       RoleFinance,
+			permission: PermissionTopup,
+			expected:   true,
+		},
+		{
+			name:       "should let finance read transactions",
+			role:       RoleFinance,
+			permission: PermissionReadTransactions,
+			expected:   true,
+		},
+		{
+			name:       "should let finance run payroll",
+			role:       RoleFinance,
+			permission: PermissionManagePayroll,
+			expected:   true,
+		},
+		{
+			name:       "should forbid hr to run payroll",
+			role:       RoleHR,
+			permission: PermissionManagePayroll,
+		},
+		{
+			name:       "should forbid finance to reverse transactions",
+			role:       RoleFinance,
+			permission: PermissionReverseTransaction,
+		},
+		{
+			name:       "should forbid finance to manage employees",
+			role:       RoleFinance,
+			permission: PermissionManageEmployees,
+		},
+		{
+			name:       "should let employee read own profile",
+			role:       RoleEmployee,
+			permission: PermissionReadOwnProfile,
+			expected:   true,
+		},
+		{
+			name:       "should forbid employee to manage employees",
+			role:       RoleEmployee,
+			permission: PermissionManageEmployees,
+		},
+		{
+			name:       "should forbid unknown role",
+			role:       "guest",
+			permission: PermissionReadOwnProfile,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, RoleCan(test.role, test.permission))
+		})
+	}
+}
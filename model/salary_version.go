@@ -0,0 +1,33 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// SalaryVersion is the salary of a position from EffectiveFrom until the
+	// next version of the same position takes effect. A version scheduled for
+	// a later date stays pending until it is applied to the position, and
+	// can be cancelled until it takes effect.
+	SalaryVersion struct {
+		ID            int        `json:"id"`
+		PositionID    int        `json:"position_id" gorm:"index:idx_salary_versions_position_effective"`
+		Salary        int        `json:"salary"`
+		EffectiveFrom time.Time  `json:"effective_from" gorm:"index:idx_salary_versions_position_effective"`
+		AppliedAt     *time.Time `json:"applied_at"`
+		CancelledAt   *time.Time `json:"cancelled_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+	}
+
+	SalaryVersionRepository interface {
+		Create(ctx context.Context, version *SalaryVersion) (*SalaryVersion, error)
+		FindEffective(ctx context.Context, positionID int, before time.Time) (*SalaryVersion, error)
+		FetchByPosition(ctx context.Context, positionID int) ([]*SalaryVersion, error)
+		FetchPending(ctx context.Context, positionID int) ([]*SalaryVersion, error)
+		FetchDue(ctx context.Context, at time.Time) ([]*SalaryVersion, error)
+		MarkApplied(ctx context.Context, id int, appliedAt time.Time) error
+		Cancel(ctx context.Context, positionID, id int, at time.Time) error
+	}
+)
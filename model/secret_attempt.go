@@ -0,0 +1,32 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// SecretAttempt is a failed secret id check, kept to throttle clients
+	// that guess secrets across many employees. UserID is zero when the
+	// employee id itself matched no employee.
+	SecretAttempt struct {
+		ID        int       `json:"id"`
+		UserID    int       `json:"user_id" gorm:"index"`
+		IP        string    `json:"ip" gorm:"index"`
+		CreatedAt time.Time `json:"created_at" gorm:"index"`
+	}
+
+	// LockoutPolicy limits failed secret id checks. An employee is locked for
+	// Cooldown after MaxAttempts failures in a row, and a client IP is refused
+	// once it failed IPMaxAttempts times within the last Cooldown.
+	LockoutPolicy struct {
+		MaxAttempts   int
+		IPMaxAttempts int
+		Cooldown      time.Duration
+	}
+
+	SecretAttemptRepository interface {
+		Create(ctx context.Context, attempt *SecretAttempt) error
+		CountByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+	}
+)
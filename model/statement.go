@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type (
+	// Statement lists the completed ledger entries of a date range the way a
+	// bank statement does, with the balance carried after every entry. All
+	// balances are computed from the ledger, not from Company.Balance.
+	Statement struct {
+		From           string            `json:"from"`
+		To             string            `json:"to"`
+		OpeningBalance int               `json:"opening_balance"`
+		TotalCredit    int               `json:"total_credit"`
+		TotalDebit     int               `json:"total_debit"`
+		ClosingBalance int               `json:"closing_balance"`
+		Entries        []*StatementEntry `json:"entries"`
+	}
+
+	StatementEntry struct {
+		TransactionID int       `json:"transaction_id"`
+		Date          time.Time `json:"date"`
+		Reference     string    `json:"reference"`
+		Description   string    `json:"description"`
+		Category      string    `json:"category"`
+		Debit         int       `json:"debit"`
+		Credit        int       `json:"credit"`
+		Balance       int       `json:"balance"`
+	}
+)
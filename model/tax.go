@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Tax statuses follow the PTKP (non-taxable income) categories of PPh 21: TK
+// is single and K married, followed by the number of dependants.
+const (
+	TaxStatusTK0 = "TK/0"
+	TaxStatusTK1 = "TK/1"
+	TaxStatusTK2 = "TK/2"
+	TaxStatusTK3 = "TK/3"
+	TaxStatusK0  = "K/0"
+	TaxStatusK1  = "K/1"
+	TaxStatusK2  = "K/2"
+	TaxStatusK3  = "K/3"
+)
+
+type (
+	// TaxableSalary is a salary about to be paid out with the contributions
+	// paid along with it, together with what was paid, contributed and
+	// withheld earlier in the same tax year.
+	TaxableSalary struct {
+		TaxStatus                   string
+		Frequency                   string
+		Period                      PayPeriod
+		GrossPay                    int
+		Contributions               []Contribution
+		YearGrossPay                int
+		YearTaxableBenefits         int
+		YearDeductibleContributions int
+		YearWithheld                int
+	}
+
+	// TaxCalculator works out the income tax of salaries.
+	TaxCalculator interface {
+		// Withhold returns the tax to withhold from salary.
+		Withhold(salary TaxableSalary) int
+		// AnnualTax returns the tax owed on grossIncome, taxable benefits
+		// included, earned over a whole tax year, deductions being the
+		// contributions of the employee that are not taxed.
+		AnnualTax(taxStatus string, grossIncome, deductions int) int
+	}
+
+	// TaxSummary adds up the salaries paid to an employee in a tax year, the
+	// contributions that count towards their income tax and the tax withheld
+	// from them. Outstanding is what is left to withhold, or when negative
+	// what was withheld too much, against the tax owed on the whole year.
+	TaxSummary struct {
+		UserID                  int           `json:"user_id"`
+		Year                    int           `json:"year"`
+		TaxStatus               string        `json:"tax_status"`
+		GrossIncome             int           `json:"gross_income"`
+		TaxableBenefits         int           `json:"taxable_benefits"`
+		DeductibleContributions int           `json:"deductible_contributions"`
+		Withheld                int           `json:"withheld"`
+		AnnualTax               int           `json:"annual_tax"`
+		Outstanding             int           `json:"outstanding"`
+		Withdrawals             []*Withdrawal `json:"withdrawals"`
+	}
+)
+
+// TaxYear returns the tax year period belongs to, the year it starts in.
+func (p PayPeriod) TaxYear() int {
+	return p.Start.Year()
+}
+
+// TaxYearStart returns the first day of the tax year of period.
+func (p PayPeriod) TaxYearStart() time.Time {
+	return time.Date(p.TaxYear(), time.January, 1, 0, 0, 0, 0, p.Start.Location())
+}
+
+// EndsTaxYear reports whether period is the last one of its tax year, the one
+// the annual tax is settled in.
+func (p PayPeriod) EndsTaxYear() bool {
+	return !p.End.Before(p.TaxYearStart().AddDate(1, 0, 0))
+}
@@ -2,29 +2,78 @@ package model
 
 import (
 	"context"
+	"self-payrol/request"
+	"self-payrol/response"
 	"time"
 )
 
 const (
 	TransactionTypeDebit   = "debit"
 	TransactionsTypeCredit = "credit"
+
+	TransactionStatusCompleted = "completed"
+	TransactionStatusRejected  = "rejected"
+
+	TransactionCategorySalaryWithdrawal     = "salary_withdrawal"
+	TransactionCategoryTax                  = "tax"
+	TransactionCategoryEmployeeContribution = "employee_contribution"
+	TransactionCategoryEmployerContribution = "employer_contribution"
+	TransactionCategoryTopup                = "topup"
+	TransactionCategoryOpeningBalance       = "opening_balance"
+	TransactionCategoryAdjustment           = "adjustment"
+	TransactionCategoryReconciliation       = "reconciliation"
+	TransactionCategoryReversal             = "reversal"
 )
 
 type (
 	Transaction struct {
-		ID        int       `json:"id"`
-		Amount    int       `json:"amount"`
-		Note      string    `json:"note"`
-		Type      string    `json:"type"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
+		ID             int        `json:"id"`
+		Amount         int        `json:"amount"`
+		Note           string     `json:"note"`
+		Type           string     `json:"type"`
+		Status         string     `json:"status" gorm:"default:completed"`
+		Category       string     `json:"category" gorm:"index"`
+		Reference      string     `json:"reference"`
+		UserID         *int       `json:"user_id" gorm:"index"`
+		PositionID     *int       `json:"position_id"`
+		IdempotencyKey string     `json:"idempotency_key,omitempty" gorm:"index"`
+		ReversalOfID   *int       `json:"reversal_of_id,omitempty" gorm:"index"`
+		ReversedAt     *time.Time `json:"reversed_at,omitempty"`
+		CreatedAt      time.Time  `json:"created_at"`
+		UpdatedAt      time.Time  `json:"updated_at"`
 	}
 
 	TransactionRepository interface {
-		Fetch(ctx context.Context, limit, offset int) ([]*Transaction, error)
+		Create(ctx context.Context, transaction *Transaction) (*Transaction, error)
+		Fetch(ctx context.Context, filter request.TransactionFilter) ([]*Transaction, error)
+		Count(ctx context.Context, filter request.TransactionFilter) (int64, error)
+		LedgerBalance(ctx context.Context, until time.Time) (int, error)
+		FetchBetween(ctx context.Context, from, to time.Time) ([]*Transaction, error)
+		FindByID(ctx context.Context, id int) (*Transaction, error)
+		FetchUnreversed(ctx context.Context, references []string) ([]*Transaction, error)
+		MarkReversed(ctx context.Context, id int, reversedAt time.Time) error
 	}
 
 	TransactionUsecase interface {
-		Fetch(ctx context.Context, limit, offset int) ([]*Transaction, int, error)
+		Fetch(ctx context.Context, filter request.TransactionFilter) ([]*Transaction, *response.Meta, int, error)
+		Reverse(ctx context.Context, id int, req request.ReversalRequest) (*Transaction, int, error)
 	}
 )
+
+// Reversible reports whether the entry is one that can be undone: it moved
+// the company balance and is not itself a reversal. Reconciliation entries
+// only bring the ledger in line with the balance, they never moved it. The
+// tax and contributions paid with a salary are only undone together with it.
+func (t *Transaction) Reversible() bool {
+	if t.Status != TransactionStatusCompleted || t.ReversalOfID != nil {
+		return false
+	}
+
+	switch t.Category {
+	case TransactionCategoryReconciliation, TransactionCategoryTax, TransactionCategoryEmployeeContribution,
+		TransactionCategoryEmployerContribution:
+		return false
+	}
+
+	return true
+}
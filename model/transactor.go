@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+type (
+	// Transactor runs fn inside a database transaction. Repository calls made
+	// with the context handed to fn take part in that transaction, which is
+	// committed when fn returns nil and rolled back otherwise.
+	Transactor interface {
+		WithinTransaction(ctx context.Context, fn func(context.Context) error) error
+	}
+)
@@ -2,22 +2,29 @@ package model
 
 import (
 	"context"
+	"crypto/subtle"
 	"self-payrol/request"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 type (
 	User struct {
-		ID         int       `json:"id"`
-		SecretID   string    `json:"secret_id"`
-		Name       string    `json:"name"`
-		Email      string    `json:"email"`
-		Phone      string    `json:"phone"`
-		Address    string    `json:"address"`
-		PositionID int       `json:"position_id"`
-		Position   *Position `json:"position"`
-		CreatedAt  time.Time `json:"created_at"`
-		UpdatedAt  time.Time `json:"updated_at"`
+		ID                   int        `json:"id"`
+		SecretID             string     `json:"-"`
+		Name                 string     `json:"name"`
+		Email                string     `json:"email"`
+		Phone                string     `json:"phone"`
+		Address              string     `json:"address"`
+		PositionID           int        `json:"position_id"`
+		Position             *Position  `json:"position"`
+		BaseSalary           *int       `json:"base_salary"`
+		TaxStatus            string     `json:"tax_status" gorm:"default:TK/0"`
+		FailedSecretAttempts int        `json:"failed_secret_attempts"`
+		LockedUntil          *time.Time `json:"locked_until"`
+		CreatedAt            time.Time  `json:"created_at"`
+		UpdatedAt            time.Time  `json:"updated_at"`
 	}
 
 	UserRepository interface {
@@ -26,6 +33,9 @@ type (
 		FindByID(ctx context.Context, id int) (*User, error)
 		Delete(ctx context.Context, id int) error
 		Fetch(ctx context.Context, limit, offset int) ([]*User, error)
+		RegisterFailedSecret(ctx context.Context, id, maxAttempts int, lockedUntil time.Time) error
+		ResetFailedSecrets(ctx context.Context, id int) error
+		UpdateBaseSalary(ctx context.Context, id int, baseSalary *int) error
 	}
 
 	UserUsecase interface {
@@ -34,6 +44,50 @@ type (
 		DestroyUser(ctx context.Context, id int) error
 		EditUser(ctx context.Context, id int, req *request.UserRequest) (*User, error)
 		StoreUser(ctx context.Context, req *request.UserRequest) (*User, error)
-		WithdrawSalary(ctx context.Context, req *request.WithdrawRequest) error
+		WithdrawSalary(ctx context.Context, req *request.WithdrawRequest) (*PayBreakdown, error)
+		FetchWithdrawals(ctx context.Context, id, limit, offset int) ([]*Withdrawal, error)
+		ResetSecret(ctx context.Context, id int, req *request.SecretResetRequest) error
+		Unlock(ctx context.Context, id int) (*User, error)
+		VerifySecret(ctx context.Context, id int, secretID, ip string) (*User, error)
+		NextWithdrawal(ctx context.Context, id int) (*WithdrawalEligibility, error)
+		SetBaseSalary(ctx context.Context, id int, req *request.BaseSalaryRequest) (*User, error)
+		FetchPayComponents(ctx context.Context, id int) ([]*PayComponent, error)
+		AddPayComponent(ctx context.Context, id int, req *request.PayComponentRequest) (*PayComponent, error)
+		RemovePayComponent(ctx context.Context, id, componentID int) error
+		TaxSummary(ctx context.Context, id, year int) (*TaxSummary, error)
+		Payslip(ctx context.Context, id int, period string) (*Payslip, error)
 	}
 )
+
+// SetSecret stores the bcrypt hash of secret as the employee credential.
+func (u *User) SetSecret(secret string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.SecretID = string(hash)
+
+	return nil
+}
+
+// CheckSecret reports whether secret matches the employee credential. Secrets
+// written before they were hashed are compared in constant time as well.
+func (u *User) CheckSecret(secret string) bool {
+	if !u.SecretHashed() {
+		return subtle.ConstantTimeCompare([]byte(u.SecretID), []byte(secret)) == 1
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(u.SecretID), []byte(secret)) == nil
+}
+
+// Locked reports whether withdrawals of the employee are locked at t.
+func (u *User) Locked(t time.Time) bool {
+	return u.LockedUntil != nil && t.Before(*u.LockedUntil)
+}
+
+// SecretHashed reports whether the credential is stored as a bcrypt hash.
+func (u *User) SecretHashed() bool {
+	_, err := bcrypt.Cost([]byte(u.SecretID))
+	return err == nil
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	PayPeriodMonthly  = "monthly"
+	PayPeriodBiweekly = "biweekly"
+	PayPeriodWeekly   = "weekly"
+)
+
+type (
+	// PayPeriod is the span of time an employee can withdraw one salary in.
+	// Start is inclusive and End is exclusive.
+	PayPeriod struct {
+		Key   string    `json:"period"`
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	}
+
+	Withdrawal struct {
+		ID                      int       `json:"id"`
+		UserID                  int       `json:"user_id" gorm:"uniqueIndex:idx_withdrawals_user_period"`
+		Period                  string    `json:"period" gorm:"uniqueIndex:idx_withdrawals_user_period"`
+		PeriodStart             time.Time `json:"period_start"`
+		PeriodEnd               time.Time `json:"period_end"`
+		Amount                  int       `json:"amount"`
+		GrossPay                int       `json:"gross_pay"`
+		Tax                     int       `json:"tax"`
+		TaxableBenefits         int       `json:"taxable_benefits"`
+		DeductibleContributions int       `json:"deductible_contributions"`
+		TransactionID           int       `json:"transaction_id" gorm:"index"`
+		CreatedAt               time.Time `json:"created_at"`
+		UpdatedAt               time.Time `json:"updated_at"`
+	}
+
+	// WithdrawalEligibility tells an employee whether they can withdraw their
+	// salary now and otherwise when they can next. Period is the pay period
+	// the next withdrawal falls in.
+	WithdrawalEligibility struct {
+		Eligible       bool          `json:"eligible"`
+		Period         PayPeriod     `json:"period"`
+		Salary         int           `json:"salary"`
+		Pay            *PayBreakdown `json:"pay"`
+		NextEligibleAt time.Time     `json:"next_eligible_at"`
+		LockedUntil    *time.Time    `json:"locked_until,omitempty"`
+	}
+
+	WithdrawalRepository interface {
+		Create(ctx context.Context, withdrawal *Withdrawal) (*Withdrawal, error)
+		FindOverlapping(ctx context.Context, userID int, period PayPeriod) (*Withdrawal, error)
+		FetchByUser(ctx context.Context, userID, limit, offset int) ([]*Withdrawal, error)
+		FetchByUserBetween(ctx context.Context, userID int, from, to time.Time) ([]*Withdrawal, error)
+		DeleteByTransaction(ctx context.Context, transactionID int) error
+	}
+)
+
+// NewPayPeriod returns the pay period of the given frequency that t falls in.
+// Monthly periods are keyed like "2022-10" and weekly ones by ISO week like
+// "2022-W42". Bi-weekly periods pair up ISO weeks 1-2, 3-4 and so on and are
+// keyed like "2022-B21"; week 53 makes up a period on its own. Unknown
+// frequencies fall back to monthly.
+func NewPayPeriod(frequency string, t time.Time) PayPeriod {
+	switch frequency {
+	case PayPeriodWeekly:
+		year, week := t.ISOWeek()
+		start := startOfISOWeek(t)
+
+		return PayPeriod{
+			Key:   fmt.Sprintf("%d-W%02d", year, week),
+			Start: start,
+			End:   start.AddDate(0, 0, 7),
+		}
+	case PayPeriodBiweekly:
+		year, week := t.ISOWeek()
+		start := startOfISOWeek(t).AddDate(0, 0, -7*((week-1)%2))
+		end := start.AddDate(0, 0, 14)
+		if _, nextWeek := start.AddDate(0, 0, 7).ISOWeek(); nextWeek == 1 {
+			end = start.AddDate(0, 0, 7)
+		}
+
+		return PayPeriod{
+			Key:   fmt.Sprintf("%d-B%02d", year, (week+1)/2),
+			Start: start,
+			End:   end,
+		}
+	default:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+
+		return PayPeriod{
+			Key:   start.Format("2006-01"),
+			Start: start,
+			End:   start.AddDate(0, 1, 0),
+		}
+	}
+}
+
+// SalaryCutoff returns when the salary of period is looked up: the end of its
+// first day. A salary change taking effect on that day is paid for the whole
+// period, one taking effect later in the period only from the next one, so
+// the pay of a period does not depend on the day it is withdrawn.
+func (p PayPeriod) SalaryCutoff() time.Time {
+	return p.Start.AddDate(0, 0, 1)
+}
+
+func startOfISOWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPayPeriod(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name      string
+		frequency string
+		at        time.Time
+		expected  PayPeriod
+	}{
+		{
+			name:      "should use the calendar month",
+			frequency: PayPeriodMonthly,
+			at:        time.Date(2022, time.October, 16, 13, 30, 0, 0, time.UTC),
+			expected:  PayPeriod{Key: "2022-10", Start: date(2022, time.October, 1), End: date(2022, time.November, 1)},
+		},
+		{
+			name:      "should fall back to monthly",
+			frequency: "",
+			at:        date(2022, time.December, 31),
+			expected:  PayPeriod{Key: "2022-12", Start: date(2022, time.December, 1), End: date(2023, time.January, 1)},
+		},
+		{
+			name:      "should use the ISO week",
+			frequency: PayPeriodWeekly,
+			at:        date(2022, time.October, 16),
+			expected:  PayPeriod{Key: "2022-W41", Start: date(2022, time.October, 10), End: date(2022, time.October, 17)},
+		},
+		{
+			name:      "should use the ISO week year around new year",
+			frequency: PayPeriodWeekly,
+			at:        date(2021, time.January, 2),
+			expected:  PayPeriod{Key: "2020-W53", Start: date(2020, time.December, 28), End: date(2021, time.January, 4)},
+		},
+		{
+			name:      "should pair odd and even ISO weeks",
+			frequency: PayPeriodBiweekly,
+			at:        date(2022, time.October, 16),
+			expected:  PayPeriod{Key: "2022-B21", Start: date(2022, time.October, 10), End: date(2022, time.October, 24)},
+		},
+		{
+			name:      "should keep week 53 on its own",
+			frequency: PayPeriodBiweekly,
+			at:        date(2020, time.December, 30),
+			expected:  PayPeriod{Key: "2020-B27", Start: date(2020, time.December, 28), End: date(2021, time.January, 4)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewPayPeriod(test.frequency, test.at))
+		})
+	}
+}
+
+func TestPayPeriodEndsTaxYear(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		at        time.Time
+		expected  bool
+	}{
+		{
+			name:      "should end the year in December",
+			frequency: PayPeriodMonthly,
+			at:        time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+			expected:  true,
+		},
+		{
+			name:      "should not end the year in November",
+			frequency: PayPeriodMonthly,
+			at:        time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC),
+			expected:  false,
+		},
+		{
+			name:      "should end the year in the week running into January",
+			frequency: PayPeriodWeekly,
+			at:        time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+			expected:  true,
+		},
+		{
+			name:      "should not end the year in the week before",
+			frequency: PayPeriodWeekly,
+			at:        time.Date(2024, time.December, 27, 0, 0, 0, 0, time.UTC),
+			expected:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewPayPeriod(test.frequency, test.at).EndsTaxYear())
+		})
+	}
+}
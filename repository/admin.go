@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+)
+
+type adminRepository struct {
+	Cfg config.Config
+}
+
+func NewAdminRepository(cfg config.Config) model.AdminRepository {
+	return &adminRepository{Cfg: cfg}
+}
+
+func (a *adminRepository) Create(ctx context.Context, admin *model.Admin) (*model.Admin, error) {
+	if err := database(ctx, a.Cfg).Create(admin).Error; err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+func (a *adminRepository) FindByUsername(ctx context.Context, username string) (*model.Admin, error) {
+	admin := new(model.Admin)
+
+	if err := database(ctx, a.Cfg).
+		Where("username = ?", username).
+		First(admin).Error; err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+func (a *adminRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+
+	if err := database(ctx, a.Cfg).Model(&model.Admin{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
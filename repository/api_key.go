@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	Cfg config.Config
+}
+
+func NewApiKeyRepository(cfg config.Config) model.ApiKeyRepository {
+	return &apiKeyRepository{Cfg: cfg}
+}
+
+func (a *apiKeyRepository) Create(ctx context.Context, key *model.ApiKey) (*model.ApiKey, error) {
+	if err := database(ctx, a.Cfg).Create(key).Error; err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) FindByHash(ctx context.Context, hash string) (*model.ApiKey, error) {
+	key := new(model.ApiKey)
+
+	if err := database(ctx, a.Cfg).
+		Where("key_hash = ?", hash).
+		First(key).Error; err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) Fetch(ctx context.Context, limit, offset int) ([]*model.ApiKey, error) {
+	var data []*model.ApiKey
+
+	if err := database(ctx, a.Cfg).Order("id DESC").
+		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Revoke stamps the key with id as revoked. It reports
+// gorm.ErrRecordNotFound when there is no active key with id.
+func (a *apiKeyRepository) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	res := database(ctx, a.Cfg).Model(&model.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (a *apiKeyRepository) Touch(ctx context.Context, id int, usedAt time.Time) error {
+	if err := database(ctx, a.Cfg).Model(&model.ApiKey{ID: id}).
+		Update("last_used_at", usedAt).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
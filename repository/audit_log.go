@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+	"self-payrol/request"
+
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	Cfg config.Config
+}
+
+func NewAuditLogRepository(cfg config.Config) model.AuditLogRepository {
+	return &auditLogRepository{Cfg: cfg}
+}
+
+func (a *auditLogRepository) Create(ctx context.Context, log *model.AuditLog) (*model.AuditLog, error) {
+	if err := database(ctx, a.Cfg).Create(log).Error; err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+func (a *auditLogRepository) Fetch(ctx context.Context, filter request.AuditLogFilter) ([]*model.AuditLog, error) {
+	var data []*model.AuditLog
+
+	if err := filterAuditLogs(database(ctx, a.Cfg), filter).Order("created_at DESC").Order("id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (a *auditLogRepository) Count(ctx context.Context, filter request.AuditLogFilter) (int64, error) {
+	var total int64
+
+	if err := filterAuditLogs(database(ctx, a.Cfg).Model(&model.AuditLog{}), filter).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// filterAuditLogs narrows query down to the rows matching filter, leaving
+// ordering and paging to the caller.
+func filterAuditLogs(query *gorm.DB, filter request.AuditLogFilter) *gorm.DB {
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	return query
+}
@@ -7,6 +7,7 @@ import (
 	"self-payrol/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type companyRepository struct {
@@ -20,78 +21,139 @@ func NewCompanyRepository(cfg config.Config) model.CompanyRepository {
 func (c *companyRepository) Get(ctx context.Context) (*model.Company, error) {
 	company := new(model.Company)
 
-	if err := c.Cfg.Database().WithContext(ctx).First(company).Error; err != nil {
+	if err := database(ctx, c.Cfg).First(company).Error; err != nil {
 		return nil, err
 	}
 
 	return company, nil
 }
 
-func (c *companyRepository) CreateOrUpdate(ctx context.Context, company *model.Company) (*model.Company, error) {
-	companyModel := new(model.Company)
-
-	if err := c.Cfg.Database().WithContext(ctx).Debug().
-		First(&companyModel).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			if err := c.Cfg.Database().WithContext(ctx).Create(&company).Find(companyModel).Error; err != nil {
-				return nil, err
-			}
+// GetForUpdate reads the company and locks its row until the transaction
+// started by model.Transactor ends, serialising every balance change.
+func (c *companyRepository) GetForUpdate(ctx context.Context) (*model.Company, error) {
+	return c.lockCompany(database(ctx, c.Cfg))
+}
 
-			return companyModel, nil
-		}
+func (c *companyRepository) Create(ctx context.Context, company *model.Company) (*model.Company, error) {
+	if err := database(ctx, c.Cfg).Create(company).Error; err != nil {
 		return nil, err
 	}
 
-	if err := c.Cfg.Database().WithContext(ctx).
-		Model(&model.Company{ID: companyModel.ID}).Updates(company).Find(companyModel).Error; err != nil {
+	return company, nil
+}
+
+// UpdateProfile saves the descriptive fields and payroll settings of the
+// company with the given id. The balance is never written here, it only
+// moves through AddBalance and DebitBalance.
+func (c *companyRepository) UpdateProfile(ctx context.Context, id int, company *model.Company) (*model.Company, error) {
+	companyModel := new(model.Company)
+
+	if err := database(ctx, c.Cfg).Model(&model.Company{ID: id}).
+		Select("name", "address", "overdraft_limit", "pay_period").Updates(company).
+		Find(companyModel).Error; err != nil {
 		return nil, err
 	}
 
 	return companyModel, nil
 }
 
-func (c *companyRepository) DebitBalance(ctx context.Context, amount int, note string) error {
-	company, err := c.Get(ctx)
-	if err != nil {
-		return errors.New("company data not found")
-	}
+// DebitBalance subtracts transaction.Amount from the company balance and
+// writes transaction as the debit ledger entry in the same database
+// transaction. The company row is locked with SELECT ... FOR UPDATE so
+// concurrent debits are applied one at a time. A debit the balance cannot
+// cover is stored as a rejected ledger entry and reported with
+// model.ErrInsufficientBalance.
+func (c *companyRepository) DebitBalance(ctx context.Context, transaction *model.Transaction) error {
+	rejected := false
+
+	err := database(ctx, c.Cfg).Transaction(func(tx *gorm.DB) error {
+		company, err := c.lockCompany(tx)
+		if err != nil {
+			return err
+		}
 
-	company.Balance -= amount
+		transaction.Type = model.TransactionTypeDebit
+		transaction.IdempotencyKey = model.IdempotencyKeyFromContext(ctx)
 
-	if err := c.Cfg.Database().WithContext(ctx).Model(company).Updates(company).Find(company).Error; err != nil {
-		return err
+		if !company.CanDebit(transaction.Amount) {
+			rejected = true
+			transaction.Status = model.TransactionStatusRejected
 
-	}
+			return tx.Create(transaction).Error
+		}
+
+		company.Balance -= transaction.Amount
+
+		if err := tx.Model(company).Update("balance", company.Balance).Error; err != nil {
+			return err
+		}
+
+		transaction.Status = model.TransactionStatusCompleted
+
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
 
-	if err := c.Cfg.Database().WithContext(ctx).Create(&model.Transaction{
-		Amount: amount,
-		Note:   note,
-		Type:   model.TransactionTypeDebit,
-	}).Error; err != nil {
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
+	if rejected {
+		return model.ErrInsufficientBalance
+	}
+
 	return nil
 }
 
-func (c *companyRepository) AddBalance(ctx context.Context, balance int) (*model.Company, error) {
-	company, err := c.Get(ctx)
+// AddBalance adds transaction.Amount to the company balance and writes
+// transaction as the credit ledger entry in the same database transaction,
+// locking the company row like DebitBalance.
+func (c *companyRepository) AddBalance(ctx context.Context, transaction *model.Transaction) (*model.Company, error) {
+	var company *model.Company
+
+	err := database(ctx, c.Cfg).Transaction(func(tx *gorm.DB) error {
+		var err error
+		company, err = c.lockCompany(tx)
+		if err != nil {
+			return err
+		}
+
+		company.Balance += transaction.Amount
+
+		if err := tx.Model(company).Update("balance", company.Balance).Error; err != nil {
+			return err
+		}
+
+		transaction.Type = model.TransactionsTypeCredit
+		transaction.IdempotencyKey = model.IdempotencyKeyFromContext(ctx)
+		transaction.Status = model.TransactionStatusCompleted
+
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("company data not found")
+		return nil, err
 	}
 
-	company.Balance += balance
+	return company, nil
+}
 
-	if err := c.Cfg.Database().WithContext(ctx).Model(company).Updates(company).Find(company).Error; err != nil {
-		return nil, err
+// lockCompany reads the company row with a FOR UPDATE lock held until tx ends.
+// Failing to take the lock is returned as is, only a missing company is
+// reported as such.
+func (c *companyRepository) lockCompany(tx *gorm.DB) (*model.Company, error) {
+	company := new(model.Company)
 
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(company).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("company data not found")
 	}
-
-	if err := c.Cfg.Database().WithContext(ctx).Create(&model.Transaction{
-		Amount: balance,
-		Note:   "Topup balance company",
-		Type:   model.TransactionsTypeCredit,
-	}).Error; err != nil {
+	if err != nil {
 		return nil, err
 	}
 
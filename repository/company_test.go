@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"os"
+	"self-payrol/model"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testConfig struct {
+	db *gorm.DB
+}
+
+func (c *testConfig) Database() *gorm.DB         { return c.db }
+func (c *testConfig) ServiceName() string        { return "self-payrol-test" }
+func (c *testConfig) ServicePort() int           { return 0 }
+func (c *testConfig) ServiceEnvironment() string { return "test" }
+
+func (c *testConfig) ReconciliationInterval() time.Duration { return 0 }
+
+func (c *testConfig) SalaryChangeInterval() time.Duration { return 0 }
+
+func (c *testConfig) IdempotencyRetention() time.Duration { return time.Hour }
+
+func (c *testConfig) SecretLockout() model.LockoutPolicy {
+	return model.LockoutPolicy{MaxAttempts: 5, IPMaxAttempts: 20, Cooldown: time.Minute}
+}
+
+func (c *testConfig) TrustedProxies() []*net.IPNet { return nil }
+
+func (c *testConfig) Contributions() []model.ContributionRule { return nil }
+
+func (c *testConfig) JWTSecret() string                         { return "secret" }
+func (c *testConfig) TokenTTL() time.Duration                   { return time.Hour }
+func (c *testConfig) DefaultAdmin() (username, password string) { return "", "" }
+
+// newTestConfig connects to the database in TEST_DATABASE_URL and empties the
+// tables used by the repositories. The tests are skipped when it is not set
+// because row locking can only be exercised against a real PostgreSQL server.
+func newTestConfig(t *testing.T) *testConfig {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Position{}, &model.User{}, &model.Company{}, &model.Transaction{}, &model.Withdrawal{}))
+	require.NoError(t, db.Exec("TRUNCATE companies, transactions, withdrawals RESTART IDENTITY").Error)
+
+	return &testConfig{db: db}
+}
+
+func TestConcurrentDebitBalance(t *testing.T) {
+	cfg := newTestConfig(t)
+	repo := NewCompanyRepository(cfg)
+	ctx := context.Background()
+
+	const (
+		openingBalance = 1000000
+		withdrawals    = 50
+		topups         = 10
+		amount         = 10000
+	)
+
+	require.NoError(t, cfg.db.Create(&model.Company{Name: "Test Company", Address: "Cempaka St."}).Error)
+	_, err := repo.AddBalance(ctx, &model.Transaction{Amount: openingBalance, Category: model.TransactionCategoryTopup})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, withdrawals+topups)
+
+	for i := 0; i < withdrawals; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.DebitBalance(ctx, &model.Transaction{Amount: amount, Category: model.TransactionCategorySalaryWithdrawal})
+		}()
+	}
+
+	for i := 0; i < topups; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.AddBalance(ctx, &model.Transaction{Amount: amount, Category: model.TransactionCategoryTopup})
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	company, err := repo.Get(ctx)
+	require.NoError(t, err)
+
+	var ledger struct {
+		Credit int
+		Debit  int
+	}
+	require.NoError(t, cfg.db.Model(&model.Transaction{}).Select(
+		"COALESCE(SUM(CASE WHEN type = ? THEN amount END), 0) AS credit, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount END), 0) AS debit",
+		model.TransactionsTypeCredit, model.TransactionTypeDebit,
+	).Where("status = ?", model.TransactionStatusCompleted).Scan(&ledger).Error)
+
+	assert.Equal(t, openingBalance+(topups-withdrawals)*amount, company.Balance)
+	assert.Equal(t, ledger.Credit-ledger.Debit, company.Balance)
+}
+
+func TestDebitBalanceInsufficientBalance(t *testing.T) {
+	cfg := newTestConfig(t)
+	repo := NewCompanyRepository(cfg)
+	ctx := context.Background()
+
+	require.NoError(t, cfg.db.Create(&model.Company{
+		Name:           "Test Company",
+		Address:        "Cempaka St.",
+		Balance:        50000,
+		OverdraftLimit: 20000,
+	}).Error)
+
+	require.NoError(t, repo.DebitBalance(ctx, &model.Transaction{Amount: 60000, Category: model.TransactionCategorySalaryWithdrawal}))
+	assert.ErrorIs(t, repo.DebitBalance(ctx, &model.Transaction{Amount: 20000, Category: model.TransactionCategorySalaryWithdrawal}),
+		model.ErrInsufficientBalance)
+
+	company, err := repo.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, -10000, company.Balance)
+
+	var rejected []*model.Transaction
+	require.NoError(t, cfg.db.Where("status = ?", model.TransactionStatusRejected).Find(&rejected).Error)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, 20000, rejected[0].Amount)
+}
+
+func TestDebitBalanceLockErrors(t *testing.T) {
+	cfg := newTestConfig(t)
+	repo := NewCompanyRepository(cfg)
+	transactor := NewTransactor(cfg)
+	ctx := context.Background()
+
+	err := repo.DebitBalance(ctx, &model.Transaction{Amount: 10000, Category: model.TransactionCategorySalaryWithdrawal})
+	assert.EqualError(t, err, "company data not found")
+
+	require.NoError(t, cfg.db.Create(&model.Company{Name: "Test Company", Address: "Cempaka St.", Balance: 50000}).Error)
+
+	holder := cfg.db.Begin()
+	require.NoError(t, holder.Error)
+	defer holder.Rollback()
+	require.NoError(t, holder.Exec("SELECT id FROM companies FOR UPDATE").Error)
+
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := database(ctx, cfg).Exec("SET LOCAL lock_timeout = '50ms'").Error; err != nil {
+			return err
+		}
+
+		return repo.DebitBalance(ctx, &model.Transaction{Amount: 10000, Category: model.TransactionCategorySalaryWithdrawal})
+	})
+	require.Error(t, err)
+	assert.NotEqual(t, "company data not found", err.Error())
+}
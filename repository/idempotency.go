@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyRepository struct {
+	Cfg config.Config
+}
+
+func NewIdempotencyRepository(cfg config.Config) model.IdempotencyRepository {
+	return &idempotencyRepository{Cfg: cfg}
+}
+
+// Create stores key unless the same key and scope is already stored, in which
+// case model.ErrIdempotencyInProgress is returned.
+func (i *idempotencyRepository) Create(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	res := database(ctx, i.Cfg).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return nil, model.ErrIdempotencyInProgress
+	}
+
+	return key, nil
+}
+
+func (i *idempotencyRepository) Find(ctx context.Context, key, scope string) (*model.IdempotencyKey, error) {
+	idempotencyKey := new(model.IdempotencyKey)
+
+	if err := database(ctx, i.Cfg).
+		Where("key = ? AND scope = ?", key, scope).
+		First(idempotencyKey).Error; err != nil {
+		return nil, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (i *idempotencyRepository) Complete(ctx context.Context, id, statusCode int, response []byte) error {
+	if err := database(ctx, i.Cfg).Model(&model.IdempotencyKey{ID: id}).
+		Updates(map[string]interface{}{"status_code": statusCode, "response": response}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *idempotencyRepository) Delete(ctx context.Context, id int) error {
+	if err := database(ctx, i.Cfg).Delete(&model.IdempotencyKey{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+
+	"gorm.io/gorm"
+)
+
+type payComponentRepository struct {
+	Cfg config.Config
+}
+
+func NewPayComponentRepository(cfg config.Config) model.PayComponentRepository {
+	return &payComponentRepository{Cfg: cfg}
+}
+
+func (p *payComponentRepository) Create(ctx context.Context, component *model.PayComponent) (*model.PayComponent, error) {
+	if err := database(ctx, p.Cfg).Create(component).Error; err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+func (p *payComponentRepository) FetchByUser(ctx context.Context, userID int) ([]*model.PayComponent, error) {
+	var data []*model.PayComponent
+
+	if err := database(ctx, p.Cfg).Where("user_id = ?", userID).
+		Order("id ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Delete removes the component with id of the employee. It reports
+// gorm.ErrRecordNotFound when the employee has no such component.
+func (p *payComponentRepository) Delete(ctx context.Context, userID, id int) error {
+	res := database(ctx, p.Cfg).Where("user_id = ?", userID).Delete(&model.PayComponent{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+
+	"gorm.io/gorm"
+)
+
+type payrollRunRepository struct {
+	Cfg config.Config
+}
+
+func NewPayrollRunRepository(cfg config.Config) model.PayrollRunRepository {
+	return &payrollRunRepository{Cfg: cfg}
+}
+
+// Create stores run together with its items.
+func (r *payrollRunRepository) Create(ctx context.Context, run *model.PayrollRun) (*model.PayrollRun, error) {
+	if err := database(ctx, r.Cfg).Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (r *payrollRunRepository) FindByID(ctx context.Context, id int) (*model.PayrollRun, error) {
+	run := new(model.PayrollRun)
+
+	if err := database(ctx, r.Cfg).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(run, id).Error; err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// Fetch lists the runs without their items, the latest first.
+func (r *payrollRunRepository) Fetch(ctx context.Context, limit, offset int) ([]*model.PayrollRun, error) {
+	var data []*model.PayrollRun
+
+	if err := database(ctx, r.Cfg).Order("id DESC").
+		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UpdateStatus moves run to its status, along with the time and actor stamps
+// of the move, provided it is still in status from. It reports
+// model.ErrPayrollRunStatus when the run has moved on in the meantime.
+func (r *payrollRunRepository) UpdateStatus(ctx context.Context, run *model.PayrollRun, from string) error {
+	res := database(ctx, r.Cfg).Model(&model.PayrollRun{}).
+		Where("id = ? AND status = ?", run.ID, from).
+		Updates(map[string]interface{}{
+			"status":       run.Status,
+			"approved_by":  run.ApprovedBy,
+			"approved_at":  run.ApprovedAt,
+			"executed_at":  run.ExecutedAt,
+			"cancelled_at": run.CancelledAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return model.ErrPayrollRunStatus
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+)
+
+type payslipRepository struct {
+	Cfg config.Config
+}
+
+func NewPayslipRepository(cfg config.Config) model.PayslipRepository {
+	return &payslipRepository{Cfg: cfg}
+}
+
+func (p *payslipRepository) Create(ctx context.Context, payslip *model.Payslip) (*model.Payslip, error) {
+	if err := database(ctx, p.Cfg).Create(payslip).Error; err != nil {
+		return nil, err
+	}
+
+	return payslip, nil
+}
+
+func (p *payslipRepository) FindByUserAndPeriod(ctx context.Context, userID int, period string) (*model.Payslip, error) {
+	payslip := new(model.Payslip)
+
+	if err := database(ctx, p.Cfg).
+		Where("user_id = ? AND period = ?", userID, period).
+		First(payslip).Error; err != nil {
+		return nil, err
+	}
+
+	return payslip, nil
+}
+
+// DeleteByTransaction removes the payslip of the payout with transactionID,
+// once it is reversed.
+func (p *payslipRepository) DeleteByTransaction(ctx context.Context, transactionID int) error {
+	return database(ctx, p.Cfg).Where("transaction_id = ?", transactionID).Delete(&model.Payslip{}).Error
+}
@@ -17,8 +17,7 @@ func NewPositionRepository(cfg config.Config) model.PositionRepository {
 func (p *positionRepository) FindByID(ctx context.Context, id int) (*model.Position, error) {
 	position := new(model.Position)
 
-	if err := p.Cfg.Database().
-		WithContext(ctx).
+	if err := database(ctx, p.Cfg).
 		Where("id = ?", id).
 		First(position).Error; err != nil {
 		return nil, err
@@ -27,14 +26,14 @@ func (p *positionRepository) FindByID(ctx context.Context, id int) (*model.Posit
 }
 
 func (p *positionRepository) Create(ctx context.Context, position *model.Position) (*model.Position, error) {
-	if err := p.Cfg.Database().WithContext(ctx).Create(&position).Error; err != nil {
+	if err := database(ctx, p.Cfg).Create(&position).Error; err != nil {
 		return nil, err
 	}
 	return position, nil
 }
 
 func (p *positionRepository) UpdateByID(ctx context.Context, id int, position *model.Position) (*model.Position, error) {
-	if err := p.Cfg.Database().WithContext(ctx).
+	if err := database(ctx, p.Cfg).
 		Model(&model.Position{ID: id}).Updates(position).Find(position).Error; err != nil {
 		return nil, err
 	}
@@ -49,7 +48,7 @@ func (p *positionRepository) Delete(ctx context.Context, id int) error {
 		return err
 	}
 
-	if err := p.Cfg.Database().WithContext(ctx).Delete(&model.Position{}, id).Error; err != nil {
+	if err := database(ctx, p.Cfg).Delete(&model.Position{}, id).Error; err != nil {
 		return err
 	}
 
@@ -59,7 +58,7 @@ func (p *positionRepository) Delete(ctx context.Context, id int) error {
 func (p *positionRepository) Fetch(ctx context.Context, limit, offset int) ([]*model.Position, error) {
 	var data []*model.Position
 
-	if err := p.Cfg.Database().WithContext(ctx).
+	if err := database(ctx, p.Cfg).
 		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
 		return nil, err
 	}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+	"time"
+)
+
+type salaryVersionRepository struct {
+	Cfg config.Config
+}
+
+func NewSalaryVersionRepository(cfg config.Config) model.SalaryVersionRepository {
+	return &salaryVersionRepository{Cfg: cfg}
+}
+
+func (s *salaryVersionRepository) Create(ctx context.Context, version *model.SalaryVersion) (*model.SalaryVersion, error) {
+	if err := database(ctx, s.Cfg).Create(version).Error; err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// FindEffective returns the latest salary version of the position taking
+// effect before before, cancelled versions aside. It reports
+// gorm.ErrRecordNotFound when the position has no such version.
+func (s *salaryVersionRepository) FindEffective(ctx context.Context, positionID int, before time.Time) (*model.SalaryVersion, error) {
+	version := new(model.SalaryVersion)
+
+	if err := database(ctx, s.Cfg).
+		Where("position_id = ? AND effective_from < ? AND cancelled_at IS NULL", positionID, before).
+		Order("effective_from DESC").Order("id DESC").
+		First(version).Error; err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func (s *salaryVersionRepository) FetchByPosition(ctx context.Context, positionID int) ([]*model.SalaryVersion, error) {
+	var data []*model.SalaryVersion
+
+	if err := database(ctx, s.Cfg).Where("position_id = ?", positionID).
+		Order("effective_from DESC").Order("id DESC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FetchPending returns the salary versions of the position that are neither
+// applied nor cancelled, the earliest effective date first.
+func (s *salaryVersionRepository) FetchPending(ctx context.Context, positionID int) ([]*model.SalaryVersion, error) {
+	var data []*model.SalaryVersion
+
+	if err := database(ctx, s.Cfg).
+		Where("position_id = ? AND applied_at IS NULL AND cancelled_at IS NULL", positionID).
+		Order("effective_from ASC").Order("id ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FetchDue returns the pending salary versions of every position that take
+// effect at or before at.
+func (s *salaryVersionRepository) FetchDue(ctx context.Context, at time.Time) ([]*model.SalaryVersion, error) {
+	var data []*model.SalaryVersion
+
+	if err := database(ctx, s.Cfg).
+		Where("applied_at IS NULL AND cancelled_at IS NULL AND effective_from <= ?", at).
+		Order("effective_from ASC").Order("id ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *salaryVersionRepository) MarkApplied(ctx context.Context, id int, appliedAt time.Time) error {
+	return database(ctx, s.Cfg).Model(&model.SalaryVersion{}).
+		Where("id = ? AND applied_at IS NULL", id).Update("applied_at", appliedAt).Error
+}
+
+// Cancel stamps the pending salary version with id of the position as
+// cancelled. Only versions taking effect after at can be cancelled, it reports
+// model.ErrSalaryChangeNotPending for any other.
+func (s *salaryVersionRepository) Cancel(ctx context.Context, positionID, id int, at time.Time) error {
+	res := database(ctx, s.Cfg).Model(&model.SalaryVersion{}).
+		Where("id = ? AND position_id = ? AND applied_at IS NULL AND cancelled_at IS NULL AND effective_from > ?",
+			id, positionID, at).
+		Update("cancelled_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return model.ErrSalaryChangeNotPending
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+	"time"
+)
+
+type secretAttemptRepository struct {
+	Cfg config.Config
+}
+
+func NewSecretAttemptRepository(cfg config.Config) model.SecretAttemptRepository {
+	return &secretAttemptRepository{Cfg: cfg}
+}
+
+func (s *secretAttemptRepository) Create(ctx context.Context, attempt *model.SecretAttempt) error {
+	if err := database(ctx, s.Cfg).Create(attempt).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *secretAttemptRepository) CountByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var total int64
+
+	if err := database(ctx, s.Cfg).Model(&model.SecretAttempt{}).
+		Where("ip = ? AND created_at >= ?", ip, since).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
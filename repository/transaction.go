@@ -4,8 +4,15 @@ import (
 	"context"
 	"self-payrol/config"
 	"self-payrol/model"
+	"self-payrol/request"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type transactionRepository struct {
 	Cfg config.Config
 }
@@ -14,13 +21,169 @@ func NewTransactionRepository(cfg config.Config) model.TransactionRepository {
 	return &transactionRepository{Cfg: cfg}
 }
 
-func (t *transactionRepository) Fetch(ctx context.Context, limit, offset int) ([]*model.Transaction, error) {
+func (t *transactionRepository) Create(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	if err := database(ctx, t.Cfg).Create(transaction).Error; err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (t *transactionRepository) Fetch(ctx context.Context, filter request.TransactionFilter) ([]*model.Transaction, error) {
 	var data []*model.Transaction
 
-	if err := t.Cfg.Database().WithContext(ctx).
-		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
+	query, err := filterTransactions(database(ctx, t.Cfg), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	column, direction := strings.TrimPrefix(filter.Sort, "-"), "ASC"
+	if column == "" {
+		column = "created_at"
+	}
+	if filter.Sort == "" || strings.HasPrefix(filter.Sort, "-") {
+		direction = "DESC"
+	}
+
+	if err := query.Order(column + " " + direction).Order("id " + direction).
+		Limit(filter.Limit).Offset(filter.Offset).Find(&data).Error; err != nil {
 		return nil, err
 	}
 
 	return data, nil
 }
+
+func (t *transactionRepository) Count(ctx context.Context, filter request.TransactionFilter) (int64, error) {
+	var total int64
+
+	query, err := filterTransactions(database(ctx, t.Cfg).Model(&model.Transaction{}), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// LedgerBalance sums the completed ledger entries created before until,
+// counting credits as positive and debits as negative. A zero until sums
+// the whole ledger.
+func (t *transactionRepository) LedgerBalance(ctx context.Context, until time.Time) (int, error) {
+	var balance int
+
+	query := database(ctx, t.Cfg).Model(&model.Transaction{}).
+		Where("status = ?", model.TransactionStatusCompleted)
+	if !until.IsZero() {
+		query = query.Where("created_at < ?", until)
+	}
+
+	if err := query.Select("COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE -amount END), 0)",
+		model.TransactionsTypeCredit).Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// FetchBetween returns the completed ledger entries created from from up to,
+// but excluding, to in the order they were written.
+func (t *transactionRepository) FetchBetween(ctx context.Context, from, to time.Time) ([]*model.Transaction, error) {
+	var data []*model.Transaction
+
+	if err := database(ctx, t.Cfg).
+		Where("status = ? AND created_at >= ? AND created_at < ?", model.TransactionStatusCompleted, from, to).
+		Order("created_at ASC").Order("id ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (t *transactionRepository) FindByID(ctx context.Context, id int) (*model.Transaction, error) {
+	transaction := new(model.Transaction)
+
+	if err := database(ctx, t.Cfg).First(transaction, id).Error; err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// FetchUnreversed returns the completed ledger entries with one of references
+// that are not reversed yet.
+func (t *transactionRepository) FetchUnreversed(ctx context.Context, references []string) ([]*model.Transaction, error) {
+	var data []*model.Transaction
+
+	if err := database(ctx, t.Cfg).
+		Where("status = ? AND reversed_at IS NULL AND reference IN ?", model.TransactionStatusCompleted, references).
+		Order("id ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MarkReversed stamps the entry with id as reversed. It only touches entries
+// that are not reversed yet and reports model.ErrAlreadyReversed otherwise.
+func (t *transactionRepository) MarkReversed(ctx context.Context, id int, reversedAt time.Time) error {
+	result := database(ctx, t.Cfg).Model(&model.Transaction{}).
+		Where("id = ? AND reversed_at IS NULL", id).Update("reversed_at", reversedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return model.ErrAlreadyReversed
+	}
+
+	return nil
+}
+
+// filterTransactions narrows query down to the rows matching filter, leaving
+// ordering and paging to the caller.
+func filterTransactions(query *gorm.DB, filter request.TransactionFilter) (*gorm.DB, error) {
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+
+	if filter.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", filter.From, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+
+	if filter.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", filter.To, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	if filter.MinAmount != 0 {
+		query = query.Where("amount >= ?", filter.MinAmount)
+	}
+
+	if filter.MaxAmount != 0 {
+		query = query.Where("amount <= ?", filter.MaxAmount)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Note != "" {
+		query = query.Where("note ILIKE ?", "%"+likeEscaper.Replace(filter.Note)+"%")
+	}
+
+	return query, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type transactor struct {
+	Cfg config.Config
+}
+
+func NewTransactor(cfg config.Config) model.Transactor {
+	return &transactor{Cfg: cfg}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return t.Cfg.Database().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// database returns the transaction started by WithinTransaction when ctx
+// carries one, or a new session on the configured database otherwise.
+func database(ctx context.Context, cfg config.Config) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return cfg.Database().WithContext(ctx)
+}
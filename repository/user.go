@@ -4,6 +4,9 @@ import (
 	"context"
 	"self-payrol/config"
 	"self-payrol/model"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type userRepository struct {
@@ -17,7 +20,7 @@ func NewUserRepository(cfg config.Config) model.UserRepository {
 func (p *userRepository) FindByID(ctx context.Context, id int) (*model.User, error) {
 	user := new(model.User)
 
-	if err := p.Cfg.Database().WithContext(ctx).Preload("Position").First(user, id).Error; err != nil {
+	if err := database(ctx, p.Cfg).Preload("Position").First(user, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -25,7 +28,7 @@ func (p *userRepository) FindByID(ctx context.Context, id int) (*model.User, err
 }
 
 func (p *userRepository) Create(ctx context.Context, user *model.User) (*model.User, error) {
-	if err := p.Cfg.Database().WithContext(ctx).Create(user).Error; err != nil {
+	if err := database(ctx, p.Cfg).Create(user).Error; err != nil {
 		return nil, err
 	}
 
@@ -40,7 +43,7 @@ func (p *userRepository) UpdateByID(ctx context.Context, id int, user *model.Use
 		return nil, err
 	}
 
-	if err := p.Cfg.Database().WithContext(ctx).Where("id", id).Updates(user).Find(user).Error; err != nil {
+	if err := database(ctx, p.Cfg).Where("id", id).Updates(user).Find(user).Error; err != nil {
 		return nil, err
 	}
 
@@ -55,7 +58,7 @@ func (p *userRepository) Delete(ctx context.Context, id int) error {
 		return err
 	}
 
-	res := p.Cfg.Database().WithContext(ctx).
+	res := database(ctx, p.Cfg).
 		Delete(&model.User{}, id)
 	if res.Error != nil {
 
@@ -67,10 +70,45 @@ func (p *userRepository) Delete(ctx context.Context, id int) error {
 func (p *userRepository) Fetch(ctx context.Context, limit, offset int) ([]*model.User, error) {
 	var data []*model.User
 
-	if err := p.Cfg.Database().WithContext(ctx).Preload("Position").
+	if err := database(ctx, p.Cfg).Preload("Position").
 		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
 		return nil, err
 	}
 
 	return data, nil
 }
+
+// RegisterFailedSecret counts a failed secret id check of the employee with
+// id and locks their withdrawals until lockedUntil once maxAttempts failures
+// are reached. The counter is incremented in SQL so concurrent failures are
+// all counted.
+func (p *userRepository) RegisterFailedSecret(ctx context.Context, id, maxAttempts int, lockedUntil time.Time) error {
+	if err := database(ctx, p.Cfg).Model(&model.User{ID: id}).Updates(map[string]interface{}{
+		"failed_secret_attempts": gorm.Expr("failed_secret_attempts + 1"),
+		"locked_until": gorm.Expr("CASE WHEN failed_secret_attempts + 1 >= ? THEN ?::timestamptz ELSE locked_until END",
+			maxAttempts, lockedUntil),
+	}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResetFailedSecrets clears the failed secret id counter and lock of the
+// employee with id.
+func (p *userRepository) ResetFailedSecrets(ctx context.Context, id int) error {
+	if err := database(ctx, p.Cfg).Model(&model.User{ID: id}).Updates(map[string]interface{}{
+		"failed_secret_attempts": 0,
+		"locked_until":           nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateBaseSalary overrides the salary of the position of the employee with
+// id, a nil baseSalary goes back to the position salary.
+func (p *userRepository) UpdateBaseSalary(ctx context.Context, id int, baseSalary *int) error {
+	return database(ctx, p.Cfg).Model(&model.User{ID: id}).Update("base_salary", baseSalary).Error
+}
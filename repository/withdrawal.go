@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/config"
+	"self-payrol/model"
+	"time"
+)
+
+type withdrawalRepository struct {
+	Cfg config.Config
+}
+
+func NewWithdrawalRepository(cfg config.Config) model.WithdrawalRepository {
+	return &withdrawalRepository{Cfg: cfg}
+}
+
+func (w *withdrawalRepository) Create(ctx context.Context, withdrawal *model.Withdrawal) (*model.Withdrawal, error) {
+	if err := database(ctx, w.Cfg).Create(withdrawal).Error; err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// FindOverlapping finds the withdrawal of the employee with userID for a pay
+// period overlapping period. Periods are compared by their dates rather than
+// their keys, so a withdrawal made before the company changed its pay
+// frequency is still found.
+func (w *withdrawalRepository) FindOverlapping(ctx context.Context, userID int, period model.PayPeriod) (*model.Withdrawal, error) {
+	withdrawal := new(model.Withdrawal)
+
+	if err := database(ctx, w.Cfg).
+		Where("user_id = ? AND period_start < ? AND period_end > ?", userID, period.End, period.Start).
+		First(withdrawal).Error; err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+func (w *withdrawalRepository) FetchByUser(ctx context.Context, userID, limit, offset int) ([]*model.Withdrawal, error) {
+	var data []*model.Withdrawal
+
+	if err := database(ctx, w.Cfg).Where("user_id = ?", userID).Order("period_start DESC").
+		Limit(limit).Offset(offset).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FetchByUserBetween lists the withdrawals of the employee with userID for the
+// pay periods starting from from and before to, oldest first.
+func (w *withdrawalRepository) FetchByUserBetween(ctx context.Context, userID int, from, to time.Time) ([]*model.Withdrawal, error) {
+	var data []*model.Withdrawal
+
+	if err := database(ctx, w.Cfg).Where("user_id = ? AND period_start >= ? AND period_start < ?", userID, from, to).
+		Order("period_start").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// DeleteByTransaction removes the withdrawal paid out by the ledger entry with
+// transactionID, freeing its pay period for another withdrawal.
+func (w *withdrawalRepository) DeleteByTransaction(ctx context.Context, transactionID int) error {
+	return database(ctx, w.Cfg).Where("transaction_id = ?", transactionID).Delete(&model.Withdrawal{}).Error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"self-payrol/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestFindOverlappingWithdrawal(t *testing.T) {
+	cfg := newTestConfig(t)
+	repo := NewWithdrawalRepository(cfg)
+	ctx := context.Background()
+
+	october := model.NewPayPeriod(model.PayPeriodMonthly, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	_, err := repo.Create(ctx, &model.Withdrawal{
+		UserID:      1,
+		Period:      october.Key,
+		PeriodStart: october.Start,
+		PeriodEnd:   october.End,
+		Amount:      100000,
+	})
+	require.NoError(t, err)
+
+	// the company switched to weekly pay in the middle of October
+	week := model.NewPayPeriod(model.PayPeriodWeekly, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC))
+	withdrawal, err := repo.FindOverlapping(ctx, 1, week)
+	require.NoError(t, err)
+	assert.Equal(t, october.Key, withdrawal.Period)
+
+	november := model.NewPayPeriod(model.PayPeriodMonthly, october.End)
+	_, err = repo.FindOverlapping(ctx, 1, november)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repo.FindOverlapping(ctx, 2, october)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
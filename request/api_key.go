@@ -0,0 +1,22 @@
+package request
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type (
+	ApiKeyRequest struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+)
+
+// Validate checks the shape of req. Whether each scope may be granted to an
+// API key is checked against model.ApiKeyScopes when the key is created.
+func (req ApiKeyRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Scopes, validation.Required),
+	)
+}
@@ -0,0 +1,28 @@
+package request
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// AuditLogFilter holds the GET /audit-logs query. Actor is written the way it
+// is stored on the logs, such as "admin:1" or "api_key:3".
+type AuditLogFilter struct {
+	EntityType string `query:"entity_type"`
+	EntityID   int    `query:"entity_id"`
+	Actor      string `query:"actor"`
+	Action     string `query:"action"`
+	Limit      int    `query:"limit"`
+	Offset     int    `query:"offset"`
+}
+
+func (req AuditLogFilter) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.EntityType, validation.In("position", "user", "company", "salary_change", "pay_component",
+			"payroll_run")),
+		validation.Field(&req.EntityID, validation.Min(0)),
+		validation.Field(&req.Action, validation.In("create", "update", "delete")),
+		validation.Field(&req.Limit, validation.Min(0)),
+		validation.Field(&req.Offset, validation.Min(0)),
+	)
+}
@@ -0,0 +1,53 @@
+package request
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type (
+	LoginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	// EmployeeLoginRequest logs an employee in with their secret id. IP is
+	// the client address, filled in by the handler.
+	EmployeeLoginRequest struct {
+		ID       int    `json:"id"`
+		SecretID string `json:"secret_id"`
+		IP       string `json:"-"`
+	}
+
+	AdminRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+	}
+)
+
+func (req LoginRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Username, validation.Required),
+		validation.Field(&req.Password, validation.Required),
+	)
+}
+
+func (req EmployeeLoginRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.ID, validation.Required),
+		validation.Field(&req.SecretID, validation.Required),
+	)
+}
+
+func (req AdminRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Username, validation.Required, validation.Length(3, 64)),
+		validation.Field(&req.Password, validation.Required, validation.Length(8, 72)),
+		validation.Field(&req.Name, validation.Required),
+		validation.Field(&req.Role, validation.Required, validation.In("admin", "hr", "finance")),
+	)
+}
@@ -3,14 +3,44 @@ package request
 import validation "github.com/go-ozzo/ozzo-validation"
 
 type (
+	// CompanyRequest sets the company profile. OverdraftLimit and PayPeriod
+	// keep their current value on an update when they are left out.
 	CompanyRequest struct {
-		Name    string `json:"name"`
-		Balance int    `json:"balance"`
-		Address string `json:"address"`
+		Name           string `json:"name"`
+		Balance        int    `json:"balance"`
+		Address        string `json:"address"`
+		OverdraftLimit *int   `json:"overdraft_limit"`
+		PayPeriod      string `json:"pay_period"`
 	}
 
 	TopupCompanyBalance struct {
-		Balance int `json:"balance" validate:"required"`
+		Balance   int    `json:"balance" validate:"required"`
+		Reference string `json:"reference"`
+	}
+
+	AdjustmentRequest struct {
+		Reason string `json:"reason"`
+	}
+
+	// BalanceAdjustmentRequest moves the company balance by Amount, which is
+	// negative for a debit, and records Reason on the ledger entry.
+	BalanceAdjustmentRequest struct {
+		Amount int    `json:"amount"`
+		Reason string `json:"reason"`
+	}
+
+	// StatementRequest selects the days from From to To, both inclusive and
+	// formatted as 2006-01-02. Format is either json (the default) or csv.
+	StatementRequest struct {
+		From   string `query:"from"`
+		To     string `query:"to"`
+		Format string `query:"format"`
+	}
+
+	// ForecastRequest picks how many pay periods, starting with the current
+	// one, a funding forecast covers. It is 3 when left empty.
+	ForecastRequest struct {
+		Periods int `query:"periods"`
 	}
 )
 
@@ -18,14 +48,47 @@ func (req CompanyRequest) Validate() error {
 	return validation.ValidateStruct(
 		&req,
 		validation.Field(&req.Name, validation.Required),
-		validation.Field(&req.Balance, validation.Required),
+		validation.Field(&req.Balance, validation.Min(0)),
 		validation.Field(&req.Address, validation.Required),
+		validation.Field(&req.OverdraftLimit, validation.Min(0)),
+		validation.Field(&req.PayPeriod, validation.In("monthly", "biweekly", "weekly")),
 	)
 }
 
 func (req TopupCompanyBalance) Validate() error {
 	return validation.ValidateStruct(
 		&req,
-		validation.Field(&req.Balance, validation.Required),
+		validation.Field(&req.Balance, validation.Required, validation.Min(1)),
+	)
+}
+
+func (req StatementRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.From, validation.Required, validation.Date("2006-01-02")),
+		validation.Field(&req.To, validation.Required, validation.Date("2006-01-02")),
+		validation.Field(&req.Format, validation.In("json", "csv")),
+	)
+}
+
+func (req ForecastRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Periods, validation.Min(0), validation.Max(52)),
+	)
+}
+
+func (req AdjustmentRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+func (req BalanceAdjustmentRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Amount, validation.Required),
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
 	)
 }
@@ -0,0 +1,17 @@
+package request
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// PayrollRunRequest drafts the payroll run of the pay period Date falls in, a
+// date formatted as 2006-01-02. It is the current pay period when Date is left
+// empty.
+type PayrollRunRequest struct {
+	Date string `json:"date"`
+}
+
+func (req PayrollRunRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Date, validation.Date("2006-01-02")),
+	)
+}
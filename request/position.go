@@ -5,9 +5,19 @@ import (
 )
 
 type (
+	// PositionRequest sets a position. EffectiveFrom is the date formatted as
+	// 2006-01-02 the salary applies from, today when it is left empty.
 	PositionRequest struct {
-		Name   string `json:"name" validate:"required"`
-		Salary int    `json:"salary" validate:"required"`
+		Name          string `json:"name" validate:"required"`
+		Salary        int    `json:"salary" validate:"required"`
+		EffectiveFrom string `json:"effective_from"`
+	}
+
+	// SalaryChangeRequest schedules a salary taking effect on EffectiveFrom,
+	// a date after today formatted as 2006-01-02.
+	SalaryChangeRequest struct {
+		Salary        int    `json:"salary"`
+		EffectiveFrom string `json:"effective_from"`
 	}
 )
 
@@ -16,5 +26,14 @@ func (req PositionRequest) Validate() error {
 		&req,
 		validation.Field(&req.Name, validation.Required),
 		validation.Field(&req.Salary, validation.Required),
+		validation.Field(&req.EffectiveFrom, validation.Date("2006-01-02")),
+	)
+}
+
+func (req SalaryChangeRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Salary, validation.Required, validation.Min(1)),
+		validation.Field(&req.EffectiveFrom, validation.Required, validation.Date("2006-01-02")),
 	)
 }
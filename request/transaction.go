@@ -0,0 +1,49 @@
+package request
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type (
+	// TransactionFilter holds the GET /transactions query. From and To are
+	// dates formatted as 2006-01-02 and both are inclusive. Sort is a column
+	// name optionally prefixed with "-" for descending order.
+	TransactionFilter struct {
+		Type      string `query:"type"`
+		Category  string `query:"category"`
+		From      string `query:"from"`
+		To        string `query:"to"`
+		MinAmount int    `query:"min_amount"`
+		MaxAmount int    `query:"max_amount"`
+		UserID    int    `query:"user_id"`
+		Note      string `query:"note"`
+		Sort      string `query:"sort"`
+		Limit     int    `query:"limit"`
+		Offset    int    `query:"offset"`
+	}
+
+	ReversalRequest struct {
+		Reason string `json:"reason"`
+	}
+)
+
+func (req TransactionFilter) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Type, validation.In("debit", "credit")),
+		validation.Field(&req.From, validation.Date("2006-01-02")),
+		validation.Field(&req.To, validation.Date("2006-01-02")),
+		validation.Field(&req.MinAmount, validation.Min(0)),
+		validation.Field(&req.MaxAmount, validation.Min(0)),
+		validation.Field(&req.Sort, validation.In("created_at", "-created_at", "amount", "-amount")),
+		validation.Field(&req.Limit, validation.Min(0)),
+		validation.Field(&req.Offset, validation.Min(0)),
+	)
+}
+
+func (req ReversalRequest) Validate() error {
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.Reason, validation.Required, validation.Length(1, 255)),
+	)
+}
@@ -1,6 +1,8 @@
 package request
 
 import (
+	"errors"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
@@ -13,11 +15,37 @@ type (
 		Phone      string `json:"phone"`
 		Address    string `json:"address"`
 		PositionID int    `json:"position_id"`
+		TaxStatus  string `json:"tax_status"`
+	}
+
+	SecretResetRequest struct {
+		SecretID string `json:"secret_id"`
+	}
+
+	// BaseSalaryRequest overrides the position salary of an employee, a null
+	// base salary removes the override.
+	BaseSalaryRequest struct {
+		BaseSalary *int `json:"base_salary"`
 	}
 
+	// PayslipRequest picks the format of a payslip, either json (the default)
+	// or html.
+	PayslipRequest struct {
+		Format string `query:"format"`
+	}
+
+	PayComponentRequest struct {
+		Name   string `json:"name"`
+		Type   string `json:"type"`
+		Amount int    `json:"amount"`
+	}
+
+	// WithdrawRequest is sent by the employee. IP is the client address,
+	// filled in by the handler to throttle secret id guessing.
 	WithdrawRequest struct {
 		ID       int    `json:"id"`
 		SecretID string `json:"secret_id"`
+		IP       string `json:"-"`
 	}
 )
 
@@ -28,14 +56,72 @@ func (req WithdrawRequest) Validate() error {
 	)
 }
 
+func (req BaseSalaryRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.BaseSalary, validation.Min(1)),
+	)
+}
+
+func (req PayComponentRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Type, validation.Required, validation.In("allowance", "deduction")),
+		validation.Field(&req.Amount, validation.Required, validation.Min(1)),
+	)
+}
+
+func (req PayslipRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Format, validation.In("json", "html")),
+	)
+}
+
+func (req SecretResetRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SecretID, validation.Required, validation.Length(6, 72)),
+	)
+}
+
+// Validate checks the employee fields shared by create and edit. The secret id
+// is checked by ValidateCreate and ValidateEdit.
 func (req UserRequest) Validate() error {
 	return validation.ValidateStruct(
 		&req,
-		validation.Field(&req.SecretID, validation.Required),
 		validation.Field(&req.Name, validation.Required),
 		validation.Field(&req.Email, validation.Required),
 		validation.Field(&req.Phone, validation.Required),
 		validation.Field(&req.Address, validation.Required),
 		validation.Field(&req.PositionID, validation.Required),
+		validation.Field(&req.TaxStatus, validation.In("TK/0", "TK/1", "TK/2", "TK/3", "K/0", "K/1", "K/2", "K/3")),
+	)
+}
+
+func (req UserRequest) ValidateCreate() error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.SecretID, validation.Required, validation.Length(6, 72)),
+	)
+}
+
+// ValidateEdit refuses a secret id, which is only changed through the secret
+// reset endpoint so the change is not silently ignored.
+func (req UserRequest) ValidateEdit() error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	return validation.ValidateStruct(
+		&req,
+		validation.Field(&req.SecretID, validation.By(func(value interface{}) error {
+			if value.(string) != "" {
+				return errors.New("cannot be edited, use POST /employee/:id/secret/reset instead")
+			}
+
+			return nil
+		})),
 	)
 }
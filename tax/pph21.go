@@ -0,0 +1,183 @@
+// Package tax holds the income tax calculators salaries can be withheld with.
+package tax
+
+import (
+	"self-payrol/model"
+)
+
+const (
+	// jobExpenseRate and maxJobExpense bound the job expense (biaya jabatan)
+	// deducted from the yearly gross income, 5% up to 6,000,000 a year.
+	jobExpenseRate = 5
+	maxJobExpense  = 6000000
+
+	basePTKP      = 54000000
+	marriedPTKP   = 4500000
+	dependantPTKP = 4500000
+)
+
+// bracket applies rate, in hundredths of a percent, to income up to upTo. The
+// last bracket of a table has no upper bound.
+type bracket struct {
+	upTo int
+	rate int
+}
+
+// progressiveRates are the yearly rates of article 17 of the income tax law,
+// as amended by the HPP law.
+var progressiveRates = []bracket{
+	{upTo: 60000000, rate: 500},
+	{upTo: 250000000, rate: 1500},
+	{upTo: 500000000, rate: 2500},
+	{upTo: 5000000000, rate: 3000},
+	{rate: 3500},
+}
+
+// Monthly effective rates (TER) of PP 58/2023. Category A is for TK/0, TK/1 and
+// K/0, B for TK/2, TK/3, K/1 and K/2, and C for K/3.
+var (
+	terA = []bracket{
+		{5400000, 0}, {5650000, 25}, {5950000, 50}, {6300000, 75}, {6750000, 100}, {7500000, 125},
+		{8550000, 150}, {9650000, 175}, {10050000, 200}, {10350000, 225}, {10700000, 250},
+		{11050000, 300}, {11600000, 350}, {12500000, 400}, {13750000, 500}, {15100000, 600},
+		{16950000, 700}, {19750000, 800}, {24150000, 900}, {26450000, 1000}, {28000000, 1100},
+		{30050000, 1200}, {32400000, 1300}, {35400000, 1400}, {39100000, 1500}, {43850000, 1600},
+		{47800000, 1700}, {51400000, 1800}, {56300000, 1900}, {62200000, 2000}, {68600000, 2100},
+		{77500000, 2200}, {89000000, 2300}, {103000000, 2400}, {125000000, 2500}, {157000000, 2600},
+		{206000000, 2700}, {337000000, 2800}, {454000000, 2900}, {550000000, 3000}, {695000000, 3100},
+		{910000000, 3200}, {1400000000, 3300}, {0, 3400},
+	}
+	terB = []bracket{
+		{6200000, 0}, {6500000, 25}, {6850000, 50}, {7300000, 75}, {9200000, 100}, {10750000, 150},
+		{11250000, 200}, {11600000, 250}, {12600000, 300}, {13600000, 400}, {14950000, 500},
+		{16400000, 600}, {18450000, 700}, {21850000, 800}, {26000000, 900}, {27700000, 1000},
+		{29350000, 1100}, {31450000, 1200}, {33950000, 1300}, {37100000, 1400}, {41100000, 1500},
+		{45800000, 1600}, {49500000, 1700}, {53800000, 1800}, {58500000, 1900}, {64000000, 2000},
+		{71000000, 2100}, {80000000, 2200}, {93000000, 2300}, {109000000, 2400}, {129000000, 2500},
+		{163000000, 2600}, {211000000, 2700}, {374000000, 2800}, {459000000, 2900}, {555000000, 3000},
+		{704000000, 3100}, {957000000, 3200}, {1405000000, 3300}, {0, 3400},
+	}
+	terC = []bracket{
+		{6600000, 0}, {6950000, 25}, {7350000, 50}, {7800000, 75}, {8850000, 100}, {9800000, 125},
+		{10950000, 150}, {11200000, 175}, {12050000, 200}, {12950000, 300}, {14150000, 400},
+		{15550000, 500}, {17050000, 600}, {19500000, 700}, {22700000, 800}, {26600000, 900},
+		{28100000, 1000}, {30100000, 1100}, {32600000, 1200}, {35400000, 1300}, {38900000, 1400},
+		{43000000, 1500}, {47400000, 1600}, {51200000, 1700}, {55800000, 1800}, {60400000, 1900},
+		{66700000, 2000}, {74500000, 2100}, {83200000, 2200}, {95600000, 2300}, {110000000, 2400},
+		{134000000, 2500}, {169000000, 2600}, {221000000, 2700}, {390000000, 2800}, {463000000, 2900},
+		{561000000, 3000}, {709000000, 3100}, {965000000, 3200}, {1419000000, 3300}, {0, 3400},
+	}
+)
+
+type pph21 struct{}
+
+// NewPPh21 returns the calculator of the Indonesian employee income tax. The
+// BPJS Kesehatan premium the company pays for the employee is taxed with their
+// salary, and their own JHT and JP contributions are deducted from the yearly
+// income. Every salary but the last of the tax year is withheld at the monthly
+// effective rate (TER) of the tax status of the employee. The last one settles
+// the year: it withholds the yearly tax on the income of the year at the
+// progressive rates, less what was withheld before.
+func NewPPh21() model.TaxCalculator {
+	return &pph21{}
+}
+
+func (p *pph21) Withhold(salary model.TaxableSalary) int {
+	grossPay := salary.GrossPay + model.TaxableBenefits(salary.Contributions)
+
+	if salary.Period.EndsTaxYear() {
+		grossIncome := salary.YearGrossPay + salary.YearTaxableBenefits + grossPay
+		deductions := salary.YearDeductibleContributions + model.DeductibleContributions(salary.Contributions)
+
+		tax := p.AnnualTax(salary.TaxStatus, grossIncome, deductions) - salary.YearWithheld
+
+		// Tax withheld too much is refunded outside of payroll.
+		if tax < 0 {
+			return 0
+		}
+
+		return tax
+	}
+
+	rate := effectiveRate(salary.TaxStatus, monthlyGrossPay(grossPay, salary.Frequency))
+
+	return grossPay * rate / 10000
+}
+
+func (p *pph21) AnnualTax(taxStatus string, grossIncome, deductions int) int {
+	jobExpense := grossIncome * jobExpenseRate / 100
+	if jobExpense > maxJobExpense {
+		jobExpense = maxJobExpense
+	}
+
+	// taxable income is rounded down to the thousand
+	taxable := (grossIncome - jobExpense - deductions - PTKP(taxStatus)) / 1000 * 1000
+	if taxable <= 0 {
+		return 0
+	}
+
+	tax, lower := 0, 0
+	for _, b := range progressiveRates {
+		upper := b.upTo
+		if upper == 0 || taxable < upper {
+			upper = taxable
+		}
+
+		tax += (upper - lower) * b.rate / 10000
+		lower = upper
+
+		if lower == taxable {
+			break
+		}
+	}
+
+	return tax
+}
+
+// PTKP returns the yearly non-taxable income of taxStatus. Unknown statuses
+// count as TK/0.
+func PTKP(taxStatus string) int {
+	switch taxStatus {
+	case model.TaxStatusTK1:
+		return basePTKP + dependantPTKP
+	case model.TaxStatusTK2:
+		return basePTKP + 2*dependantPTKP
+	case model.TaxStatusTK3:
+		return basePTKP + 3*dependantPTKP
+	case model.TaxStatusK0:
+		return basePTKP + marriedPTKP
+	case model.TaxStatusK1:
+		return basePTKP + marriedPTKP + dependantPTKP
+	case model.TaxStatusK2:
+		return basePTKP + marriedPTKP + 2*dependantPTKP
+	case model.TaxStatusK3:
+		return basePTKP + marriedPTKP + 3*dependantPTKP
+	default:
+		return basePTKP
+	}
+}
+
+// effectiveRate looks the monthly gross pay up in the TER table of taxStatus.
+func effectiveRate(taxStatus string, monthlyGrossPay int) int {
+	table := terA
+	switch taxStatus {
+	case model.TaxStatusTK2, model.TaxStatusTK3, model.TaxStatusK1, model.TaxStatusK2:
+		table = terB
+	case model.TaxStatusK3:
+		table = terC
+	}
+
+	for _, b := range table {
+		if b.upTo == 0 || monthlyGrossPay <= b.upTo {
+			return b.rate
+		}
+	}
+
+	return 0
+}
+
+// monthlyGrossPay scales the gross pay of a weekly or bi-weekly salary to a
+// month, the TER tables being monthly.
+func monthlyGrossPay(grossPay int, frequency string) int {
+	return grossPay * model.PeriodsPerYear(frequency) / 12
+}
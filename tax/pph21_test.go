@@ -0,0 +1,171 @@
+package tax
+
+import (
+	"self-payrol/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPPh21Withhold(t *testing.T) {
+	october := model.NewPayPeriod(model.PayPeriodMonthly, time.Date(2024, time.October, 15, 0, 0, 0, 0, time.UTC))
+	december := model.NewPayPeriod(model.PayPeriodMonthly, time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC))
+	week := model.NewPayPeriod(model.PayPeriodWeekly, time.Date(2024, time.October, 15, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name     string
+		salary   model.TaxableSalary
+		expected int
+	}{
+		{
+			name:     "should not withhold below the first TER bracket",
+			salary:   model.TaxableSalary{TaxStatus: model.TaxStatusTK0, Period: october, GrossPay: 5000000},
+			expected: 0,
+		},
+		{
+			name:     "should withhold at TER category A",
+			salary:   model.TaxableSalary{TaxStatus: model.TaxStatusTK0, Period: october, GrossPay: 10000000},
+			expected: 200000,
+		},
+		{
+			name:     "should withhold at TER category B",
+			salary:   model.TaxableSalary{TaxStatus: model.TaxStatusTK2, Period: october, GrossPay: 10000000},
+			expected: 150000,
+		},
+		{
+			name:     "should withhold at TER category C",
+			salary:   model.TaxableSalary{TaxStatus: model.TaxStatusK3, Period: october, GrossPay: 10000000},
+			expected: 150000,
+		},
+		{
+			name:     "should count unknown status as TK/0",
+			salary:   model.TaxableSalary{Period: october, GrossPay: 10000000},
+			expected: 200000,
+		},
+		{
+			name: "should tax the BPJS Kesehatan premium of the company with the salary",
+			salary: model.TaxableSalary{
+				TaxStatus: model.TaxStatusTK0,
+				Period:    october,
+				GrossPay:  9600000,
+				Contributions: []model.Contribution{
+					{Name: model.ContributionBPJSKesehatan, Wage: 9600000, Employee: 96000, Employer: 384000},
+					{Name: model.ContributionBPJSJHT, Wage: 9600000, Employee: 192000, Employer: 355200},
+				},
+			},
+			// 9,984,000 is withheld at 2% where 9,600,000 alone would be at 1.75%
+			expected: 199680,
+		},
+		{
+			name: "should look weekly salaries up by their monthly equivalent",
+			salary: model.TaxableSalary{
+				TaxStatus: model.TaxStatusTK0,
+				Frequency: model.PayPeriodWeekly,
+				Period:    week,
+				GrossPay:  2500000,
+			},
+			expected: 75000,
+		},
+		{
+			name: "should settle the annual tax in the last period of the year",
+			salary: model.TaxableSalary{
+				TaxStatus:    model.TaxStatusTK0,
+				Period:       december,
+				GrossPay:     10000000,
+				YearGrossPay: 110000000,
+				YearWithheld: 2200000,
+			},
+			expected: 800000,
+		},
+		{
+			name: "should deduct the JHT and JP contributions of the employee from the annual income",
+			salary: model.TaxableSalary{
+				TaxStatus: model.TaxStatusTK0,
+				Period:    december,
+				GrossPay:  10000000,
+				Contributions: []model.Contribution{
+					{Name: model.ContributionBPJSJHT, Wage: 10000000, Employee: 200000, Employer: 370000},
+					{Name: model.ContributionBPJSJP, Wage: 10000000, Employee: 100000, Employer: 200000},
+				},
+				YearGrossPay:                110000000,
+				YearDeductibleContributions: 3300000,
+				YearWithheld:                2200000,
+			},
+			expected: 620000,
+		},
+		{
+			name: "should add the BPJS Kesehatan premiums of the year to the annual income",
+			salary: model.TaxableSalary{
+				TaxStatus: model.TaxStatusTK0,
+				Period:    december,
+				GrossPay:  10000000,
+				Contributions: []model.Contribution{
+					{Name: model.ContributionBPJSKesehatan, Wage: 10000000, Employee: 100000, Employer: 400000},
+				},
+				YearGrossPay:        110000000,
+				YearTaxableBenefits: 4400000,
+				YearWithheld:        2200000,
+			},
+			expected: 1520000,
+		},
+		{
+			name: "should not withhold when too much was withheld during the year",
+			salary: model.TaxableSalary{
+				TaxStatus:    model.TaxStatusTK0,
+				Period:       december,
+				GrossPay:     10000000,
+				YearGrossPay: 110000000,
+				YearWithheld: 5000000,
+			},
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewPPh21().Withhold(test.salary))
+		})
+	}
+}
+
+func TestPPh21AnnualTax(t *testing.T) {
+	tests := []struct {
+		name        string
+		taxStatus   string
+		grossIncome int
+		deductions  int
+		expected    int
+	}{
+		{
+			name:        "should not tax income below PTKP",
+			taxStatus:   model.TaxStatusK1,
+			grossIncome: 60000000,
+			expected:    0,
+		},
+		{
+			name:        "should round taxable income down to the thousand",
+			taxStatus:   model.TaxStatusTK0,
+			grossIncome: 100001500,
+			expected:    2050050,
+		},
+		{
+			name:        "should cap the job expense and apply progressive rates",
+			taxStatus:   model.TaxStatusTK0,
+			grossIncome: 600000000,
+			expected:    106000000,
+		},
+		{
+			name:        "should not tax the deductions",
+			taxStatus:   model.TaxStatusTK0,
+			grossIncome: 100001500,
+			deductions:  3000000,
+			expected:    1900050,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewPPh21().AnnualTax(test.taxStatus, test.grossIncome, test.deductions))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/response"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
+)
+
+// dummyPassword is checked when the username is unknown, so a login takes as
+// long whether or not the admin exists.
+var dummyPassword = &model.Admin{Password: "$2a$10$x.BQseSW2lCuE8huSg8DtOXytaxsLxAhnJOlyuMyEAXFYucHdg1qm"}
+
+type adminUsecase struct {
+	adminRepo   model.AdminRepository
+	userUsecase model.UserUsecase
+	secret      []byte
+	ttl         time.Duration
+	issuer      string
+}
+
+func NewAdminUsecase(admin model.AdminRepository, user model.UserUsecase, secret []byte, ttl time.Duration,
+	issuer string) model.AdminUsecase {
+	return &adminUsecase{adminRepo: admin, userUsecase: user, secret: secret, ttl: ttl, issuer: issuer}
+}
+
+// Login checks the admin credentials and issues an access token with the
+// admin's role.
+func (a *adminUsecase) Login(ctx context.Context, req *request.LoginRequest) (*response.Token, error) {
+	admin, err := a.adminRepo.FindByUsername(ctx, req.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		dummyPassword.CheckPassword(req.Password)
+		return nil, model.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !admin.CheckPassword(req.Password) {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	return a.issue(&model.Claims{
+		Username:       admin.Username,
+		Role:           admin.Role,
+		StandardClaims: jwt.StandardClaims{Subject: strconv.Itoa(admin.ID)},
+	})
+}
+
+// EmployeeLogin checks the employee secret id under the same lockout policy
+// as withdrawals and issues an access token with the employee role.
+func (a *adminUsecase) EmployeeLogin(ctx context.Context, req *request.EmployeeLoginRequest) (*response.Token, error) {
+	user, err := a.userUsecase.VerifySecret(ctx, req.ID, req.SecretID, req.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.issue(&model.Claims{
+		Username:       user.Email,
+		Role:           model.RoleEmployee,
+		UserID:         user.ID,
+		StandardClaims: jwt.StandardClaims{Subject: strconv.Itoa(user.ID)},
+	})
+}
+
+// Authenticate verifies the signature and expiry of token and returns its
+// claims. Tokens signed with any other method than HS256 are refused.
+func (a *adminUsecase) Authenticate(ctx context.Context, token string) (*model.Claims, error) {
+	claims := new(model.Claims)
+
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+
+		return a.secret, nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, model.ErrUnauthorized
+	}
+
+	return claims, nil
+}
+
+// issue signs claims with HS256, valid from now for the configured time to
+// live.
+func (a *adminUsecase) issue(claims *model.Claims) (*response.Token, error) {
+	now := time.Now()
+	expiresAt := now.Add(a.ttl)
+
+	claims.Issuer = a.issuer
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (a *adminUsecase) StoreAdmin(ctx context.Context, req *request.AdminRequest) (*model.Admin, error) {
+	admin := &model.Admin{
+		Username: req.Username,
+		Name:     req.Name,
+		Role:     req.Role,
+	}
+
+	if err := admin.SetPassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	return a.adminRepo.Create(ctx, admin)
+}
+
+// EnsureAdmin creates the first admin from the given credentials when there is
+// no admin yet, so a fresh installation can be logged in to.
+func (a *adminUsecase) EnsureAdmin(ctx context.Context, username, password string) error {
+	if username == "" || password == "" {
+		return nil
+	}
+
+	total, err := a.adminRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+
+	if total > 0 {
+		return nil
+	}
+
+	_, err = a.StoreAdmin(ctx, &request.AdminRequest{
+		Username: username,
+		Password: password,
+		Name:     username,
+		Role:     model.RoleAdmin,
+	})
+
+	return err
+}
@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	adminData := &model.Admin{ID: 1, Username: "admin", Name: "Admin"}
+	require.NoError(t, adminData.SetPassword("s3cret-pass"))
+	tests := []struct {
+		name        string
+		req         *request.LoginRequest
+		findErr     error
+		expectedErr error
+	}{
+		{
+			name: "should login successfully",
+			req:  &request.LoginRequest{Username: "admin", Password: "s3cret-pass"},
+		},
+		{
+			name:        "should get invalid credentials error while password is wrong",
+			req:         &request.LoginRequest{Username: "admin", Password: "wrong-pass"},
+			expectedErr: model.ErrInvalidCredentials,
+		},
+		{
+			name:        "should get invalid credentials error while admin is not found",
+			req:         &request.LoginRequest{Username: "admin", Password: "s3cret-pass"},
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: model.ErrInvalidCredentials,
+		},
+		{
+			name:        "should get some error while find admin",
+			req:         &request.LoginRequest{Username: "admin", Password: "s3cret-pass"},
+			findErr:     errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.AdminRepository
+			useCase := NewAdminUsecase(&mockRepo, nil, []byte("secret"), time.Hour, "self-payrol")
+
+			mockRepo.On("FindByUsername", ctx, test.req.Username).Return(adminData, test.findErr).Once()
+			res, err := useCase.Login(ctx, test.req)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.Equal(t, "Bearer", res.TokenType)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), res.ExpiresAt, time.Minute)
+
+			claims, err := useCase.Authenticate(ctx, res.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "1", claims.Subject)
+			assert.Equal(t, "admin", claims.Username)
+			assert.Equal(t, "self-payrol", claims.Issuer)
+		})
+	}
+}
+
+func TestEmployeeLogin(t *testing.T) {
+	ctx := context.Background()
+	lockout := model.LockoutPolicy{MaxAttempts: 3, IPMaxAttempts: 10, Cooldown: time.Minute}
+	userData := &model.User{ID: 7, Name: "user", Email: "x@company.com", PositionID: 1}
+	require.NoError(t, userData.SetSecret("asdjksakdas"))
+	tests := []struct {
+		name        string
+		secretID    string
+		findErr     error
+		expectedErr error
+	}{
+		{
+			name:     "should login employee successfully",
+			secretID: "asdjksakdas",
+		},
+		{
+			name:        "should get some error while secret id invalid",
+			secretID:    "xxx-xxx",
+			expectedErr: errors.New("secret id not valid"),
+		},
+		{
+			name:        "should count unknown employee id as a failed login",
+			secretID:    "asdjksakdas",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo    mocks.UserRepository
+				attemptMockRepo mocks.SecretAttemptRepository
+			)
+			userUsecase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, nil, &attemptMockRepo, nil, lockout, nil, nil)
+			useCase := NewAdminUsecase(nil, userUsecase, []byte("secret"), time.Hour, "self-payrol")
+
+			attemptMockRepo.On("CountByIP", ctx, "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(0), nil).Once()
+			userMockRepo.On("FindByID", ctx, 7).Return(userData, test.findErr).Once()
+			attemptMockRepo.On("Create", ctx, &model.SecretAttempt{UserID: 7, IP: "10.0.0.1"}).Return(nil).Once()
+			attemptMockRepo.On("Create", ctx, &model.SecretAttempt{IP: "10.0.0.1"}).Return(nil).Once()
+			userMockRepo.On("RegisterFailedSecret", ctx, 7, 3, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+			res, err := useCase.EmployeeLogin(ctx, &request.EmployeeLoginRequest{ID: 7, SecretID: test.secretID, IP: "10.0.0.1"})
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				assert.Nil(t, res)
+				return
+			}
+
+			claims, err := useCase.Authenticate(ctx, res.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, model.RoleEmployee, claims.Role)
+			assert.Equal(t, 7, claims.UserID)
+			assert.Equal(t, "7", claims.Subject)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	useCase := NewAdminUsecase(nil, nil, []byte("secret"), time.Hour, "self-payrol")
+	sign := func(method jwt.SigningMethod, key interface{}, expiresAt time.Time) string {
+		token, err := jwt.NewWithClaims(method, &model.Claims{
+			Username: "admin",
+			StandardClaims: jwt.StandardClaims{
+				Subject:   "1",
+				ExpiresAt: expiresAt.Unix(),
+			},
+		}).SignedString(key)
+		require.NoError(t, err)
+
+		return token
+	}
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+	}{
+		{
+			name:  "should authenticate valid token",
+			token: sign(jwt.SigningMethodHS256, []byte("secret"), time.Now().Add(time.Hour)),
+		},
+		{
+			name:        "should refuse expired token",
+			token:       sign(jwt.SigningMethodHS256, []byte("secret"), time.Now().Add(-time.Minute)),
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should refuse token signed with another key",
+			token:       sign(jwt.SigningMethodHS256, []byte("another secret"), time.Now().Add(time.Hour)),
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should refuse token signed with another method",
+			token:       sign(jwt.SigningMethodHS512, []byte("secret"), time.Now().Add(time.Hour)),
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should refuse unsigned token",
+			token:       sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, time.Now().Add(time.Hour)),
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should refuse malformed token",
+			token:       "not-a-token",
+			expectedErr: model.ErrUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := useCase.Authenticate(ctx, test.token)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr == nil {
+				assert.Equal(t, "admin", claims.Username)
+			}
+		})
+	}
+}
+
+func TestStoreAdmin(t *testing.T) {
+	ctx := context.Background()
+	req := &request.AdminRequest{Username: "hr", Password: "s3cret-pass", Name: "HR"}
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{
+			name: "should store admin successfully",
+		},
+		{
+			name:        "should get some error",
+			err:         errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.AdminRepository
+			useCase := NewAdminUsecase(&mockRepo, nil, []byte("secret"), time.Hour, "self-payrol")
+
+			mockRepo.On("Create", ctx, mock.MatchedBy(func(admin *model.Admin) bool {
+				return admin.Username == "hr" && admin.Name == "HR" && admin.CheckPassword("s3cret-pass")
+			})).Return(func(ctx context.Context, admin *model.Admin) *model.Admin {
+				if test.err != nil {
+					return nil
+				}
+				return admin
+			}, test.err).Once()
+			res, err := useCase.StoreAdmin(ctx, req)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr == nil {
+				assert.Equal(t, "hr", res.Username)
+			}
+		})
+	}
+}
+
+func TestEnsureAdmin(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		username     string
+		total        int64
+		countErr     error
+		expectCreate bool
+		expectedErr  error
+	}{
+		{
+			name:         "should create the first admin",
+			username:     "admin",
+			expectCreate: true,
+		},
+		{
+			name:     "should keep existing admins",
+			username: "admin",
+			total:    1,
+		},
+		{
+			name: "should skip without credentials",
+		},
+		{
+			name:        "should get some error while count admins",
+			username:    "admin",
+			countErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.AdminRepository
+			useCase := NewAdminUsecase(&mockRepo, nil, []byte("secret"), time.Hour, "self-payrol")
+
+			mockRepo.On("Count", ctx).Return(test.total, test.countErr).Once()
+			mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Admin")).Return(&model.Admin{ID: 1}, nil).Once()
+			password := ""
+			if test.username != "" {
+				password = "s3cret-pass"
+			}
+			err := useCase.EnsureAdmin(ctx, test.username, password)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectCreate {
+				mockRepo.AssertCalled(t, "Create", ctx, mock.AnythingOfType("*model.Admin"))
+			} else {
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
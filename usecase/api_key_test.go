@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/usecase/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name           string
+		scopes         []string
+		err            error
+		expectedScopes []model.Permission
+		expectedErr    error
+	}{
+		{
+			name:           "should create api key successfully",
+			scopes:         []string{"employees:manage", "positions:manage"},
+			expectedScopes: []model.Permission{model.PermissionManageEmployees, model.PermissionManagePositions},
+		},
+		{
+			name:           "should create api key that runs payroll",
+			scopes:         []string{"payroll:manage"},
+			expectedScopes: []model.Permission{model.PermissionManagePayroll},
+		},
+		{
+			name:        "should refuse scope of logged in users only",
+			scopes:      []string{"employees:manage", "api_keys:manage"},
+			expectedErr: model.ErrInvalidScope,
+		},
+		{
+			name:        "should refuse unknown scope",
+			scopes:      []string{"payroll:approve"},
+			expectedErr: model.ErrInvalidScope,
+		},
+		{
+			name:        "should get some error",
+			scopes:      []string{"employees:manage"},
+			err:         errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.ApiKeyRepository
+			useCase := NewApiKeyUsecase(&mockRepo)
+
+			mockRepo.On("Create", ctx, mock.AnythingOfType("*model.ApiKey")).
+				Return(func(ctx context.Context, key *model.ApiKey) *model.ApiKey {
+					if test.err != nil {
+						return nil
+					}
+					key.ID = 1
+					return key
+				}, test.err).Once()
+			res, err := useCase.Create(ctx, &request.ApiKeyRequest{Name: "HRIS", Scopes: test.scopes})
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				assert.Nil(t, res)
+				if test.err == nil {
+					mockRepo.AssertNotCalled(t, "Create", ctx, mock.Anything)
+				}
+				return
+			}
+
+			assert.True(t, strings.HasPrefix(res.Key, "spk_"))
+			assert.True(t, strings.HasPrefix(res.Key, res.Prefix))
+			assert.Equal(t, hashApiKey(res.Key), res.KeyHash)
+			assert.Equal(t, test.expectedScopes, res.Scopes)
+		})
+	}
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	ctx := context.Background()
+	recently := time.Now().Add(-time.Second)
+	longAgo := time.Now().Add(-time.Hour)
+	scopes := []model.Permission{model.PermissionReadTransactions}
+	tests := []struct {
+		name         string
+		apiKey       *model.ApiKey
+		findErr      error
+		touchErr     error
+		expectTouch  bool
+		expectedResp *model.Claims
+		expectedErr  error
+	}{
+		{
+			name:        "should authenticate key used for the first time",
+			apiKey:      &model.ApiKey{ID: 1, Name: "accounting", Scopes: scopes},
+			expectTouch: true,
+		},
+		{
+			name:        "should authenticate key used long ago",
+			apiKey:      &model.ApiKey{ID: 1, Name: "accounting", Scopes: scopes, LastUsedAt: &longAgo},
+			expectTouch: true,
+		},
+		{
+			name:   "should not write last used time of key used just now",
+			apiKey: &model.ApiKey{ID: 1, Name: "accounting", Scopes: scopes, LastUsedAt: &recently},
+		},
+		{
+			name:        "should refuse revoked key",
+			apiKey:      &model.ApiKey{ID: 1, Name: "accounting", Scopes: scopes, RevokedAt: &recently},
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should refuse unknown key",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: model.ErrUnauthorized,
+		},
+		{
+			name:        "should get some error while find key",
+			findErr:     errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name:        "should get some error while write last used time",
+			apiKey:      &model.ApiKey{ID: 1, Name: "accounting", Scopes: scopes},
+			touchErr:    errors.New("some error"),
+			expectTouch: true,
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.ApiKeyRepository
+			useCase := NewApiKeyUsecase(&mockRepo)
+
+			mockRepo.On("FindByHash", ctx, hashApiKey("spk_key")).Return(test.apiKey, test.findErr).Once()
+			mockRepo.On("Touch", ctx, 1, mock.AnythingOfType("time.Time")).Return(test.touchErr).Once()
+			res, err := useCase.Authenticate(ctx, "spk_key")
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectTouch {
+				mockRepo.AssertCalled(t, "Touch", ctx, 1, mock.AnythingOfType("time.Time"))
+			} else {
+				mockRepo.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if test.expectedErr != nil {
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.Equal(t, "api_key:1", res.Subject)
+			assert.Empty(t, res.Role)
+			assert.True(t, res.Can(model.PermissionReadTransactions))
+			assert.False(t, res.Can(model.PermissionReverseTransaction))
+		})
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	var mockRepo mocks.ApiKeyRepository
+	useCase := NewApiKeyUsecase(&mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Revoke", ctx, 1, mock.AnythingOfType("time.Time")).Return(nil).Once()
+	mockRepo.On("Revoke", ctx, 2, mock.AnythingOfType("time.Time")).Return(gorm.ErrRecordNotFound).Once()
+
+	assert.NoError(t, useCase.Revoke(ctx, 1))
+	assert.Equal(t, gorm.ErrRecordNotFound, useCase.Revoke(ctx, 2))
+}
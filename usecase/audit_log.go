@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/response"
+)
+
+type auditLogUsecase struct {
+	auditLogRepo model.AuditLogRepository
+}
+
+func NewAuditLogUsecase(auditLog model.AuditLogRepository) model.AuditLogUsecase {
+	return &auditLogUsecase{auditLogRepo: auditLog}
+}
+
+func (a *auditLogUsecase) Fetch(ctx context.Context, filter request.AuditLogFilter) ([]*model.AuditLog, *response.Meta, int, error) {
+	logs, err := a.auditLogRepo.Fetch(ctx, filter)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	total, err := a.auditLogRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	meta := &response.Meta{
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	return logs, meta, http.StatusOK, nil
+}
+
+// audit records the change of an entity from before to after in the request
+// carried by ctx.
+func audit(ctx context.Context, repo model.AuditLogRepository, action, entityType string, entityID int,
+	before, after interface{}) error {
+	log, err := model.NewAuditLog(ctx, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Create(ctx, log)
+
+	return err
+}
@@ -2,17 +2,25 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"self-payrol/model"
 	"self-payrol/request"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type companyUsecase struct {
-	companyRepo model.CompanyRepository
+	companyRepo     model.CompanyRepository
+	transactionRepo model.TransactionRepository
+	auditLogRepo    model.AuditLogRepository
+	transactor      model.Transactor
 }
 
-func NewCompanyUsecase(repo model.CompanyRepository) model.CompanyUsecase {
-	return &companyUsecase{companyRepo: repo}
+func NewCompanyUsecase(repo model.CompanyRepository, transaction model.TransactionRepository,
+	auditLog model.AuditLogRepository, transactor model.Transactor) model.CompanyUsecase {
+	return &companyUsecase{companyRepo: repo, transactionRepo: transaction, auditLogRepo: auditLog, transactor: transactor}
 }
 
 func (c *companyUsecase) GetCompanyInfo(ctx context.Context) (*model.Company, int, error) {
@@ -23,26 +31,308 @@ func (c *companyUsecase) GetCompanyInfo(ctx context.Context) (*model.Company, in
 	return company, http.StatusOK, err
 }
 
+// CreateOrUpdateCompany creates the company on its first call, booking
+// req.Balance as an opening balance ledger entry. Later calls only update the
+// profile, a different balance is refused with model.ErrBalanceReadOnly.
 func (c *companyUsecase) CreateOrUpdateCompany(ctx context.Context, req request.CompanyRequest) (*model.Company, int, error) {
-	company, err := c.companyRepo.CreateOrUpdate(ctx, &model.Company{
-		Name:    req.Name,
-		Address: req.Address,
-		Balance: req.Balance,
-	})
+	existing, err := c.companyRepo.Get(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	profile := &model.Company{
+		Name:      req.Name,
+		Address:   req.Address,
+		PayPeriod: req.PayPeriod,
+	}
+
+	if req.OverdraftLimit != nil {
+		profile.OverdraftLimit = *req.OverdraftLimit
+	}
+
+	var company *model.Company
+
+	if existing != nil {
+		if req.Balance != 0 && req.Balance != existing.Balance {
+			return nil, http.StatusUnprocessableEntity, model.ErrBalanceReadOnly
+		}
+
+		if profile.PayPeriod == "" {
+			profile.PayPeriod = existing.PayPeriod
+		}
+
+		if req.OverdraftLimit == nil {
+			profile.OverdraftLimit = existing.OverdraftLimit
+		}
+
+		err = c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			company, err = c.companyRepo.UpdateProfile(ctx, existing.ID, profile)
+			if err != nil {
+				return err
+			}
 
+			return audit(ctx, c.auditLogRepo, model.AuditActionUpdate, model.AuditEntityCompany, company.ID, existing, company)
+		})
+		if err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+
+		return company, http.StatusOK, nil
+	}
+
+	err = c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		company, err = c.companyRepo.Create(ctx, profile)
+		if err != nil {
+			return err
+		}
+
+		if req.Balance != 0 {
+			company, err = c.companyRepo.AddBalance(ctx, &model.Transaction{
+				Amount:   req.Balance,
+				Note:     "Opening balance",
+				Category: model.TransactionCategoryOpeningBalance,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return audit(ctx, c.auditLogRepo, model.AuditActionCreate, model.AuditEntityCompany, company.ID, nil, company)
+	})
 	if err != nil {
 		return nil, http.StatusUnprocessableEntity, err
 	}
 
 	return company, http.StatusOK, nil
-
 }
 
 func (c *companyUsecase) TopupBalance(ctx context.Context, req request.TopupCompanyBalance) (*model.Company, int, error) {
-	company, err := c.companyRepo.AddBalance(ctx, req.Balance)
+	var company *model.Company
+
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		company, err = c.companyRepo.AddBalance(ctx, &model.Transaction{
+			Amount:    req.Balance,
+			Note:      "Topup balance company",
+			Category:  model.TransactionCategoryTopup,
+			Reference: req.Reference,
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.auditBalance(ctx, company, req.Balance)
+	})
 	if err != nil {
 		return nil, http.StatusUnprocessableEntity, err
 	}
 
 	return company, http.StatusOK, nil
 }
+
+// AdjustBalance credits or debits the company balance outside of the regular
+// top-up and salary flows, keeping req.Reason on the ledger entry. Debits are
+// held to the same overdraft rule as withdrawals.
+func (c *companyUsecase) AdjustBalance(ctx context.Context, req request.BalanceAdjustmentRequest) (*model.Company, int, error) {
+	transaction := &model.Transaction{
+		Amount:   req.Amount,
+		Note:     req.Reason,
+		Category: model.TransactionCategoryAdjustment,
+	}
+
+	var company *model.Company
+
+	// A rejected debit is still committed so its ledger entry is kept, the
+	// error is only returned once the transaction is done.
+	var debitErr error
+
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		if req.Amount > 0 {
+			company, err = c.companyRepo.AddBalance(ctx, transaction)
+			if err != nil {
+				return err
+			}
+
+			return c.auditBalance(ctx, company, req.Amount)
+		}
+
+		transaction.Amount = -req.Amount
+
+		err = c.companyRepo.DebitBalance(ctx, transaction)
+		if errors.Is(err, model.ErrInsufficientBalance) {
+			debitErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		company, err = c.companyRepo.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.auditBalance(ctx, company, req.Amount)
+	})
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	if debitErr != nil {
+		return nil, http.StatusUnprocessableEntity, debitErr
+	}
+
+	return company, http.StatusOK, nil
+}
+
+// auditBalance records the balance change of amount, negative for a debit,
+// that left the company as company.
+func (c *companyUsecase) auditBalance(ctx context.Context, company *model.Company, amount int) error {
+	before := *company
+	before.Balance -= amount
+
+	return audit(ctx, c.auditLogRepo, model.AuditActionUpdate, model.AuditEntityCompany, company.ID, &before, company)
+}
+
+func (c *companyUsecase) GetStatement(ctx context.Context, req request.StatementRequest) (*model.Statement, int, error) {
+	from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if to.Before(from) {
+		return nil, http.StatusBadRequest, errors.New("to date must not be before from date")
+	}
+
+	// the statement includes every entry written on the to date
+	to = to.AddDate(0, 0, 1)
+
+	opening, err := c.transactionRepo.LedgerBalance(ctx, from)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	transactions, err := c.transactionRepo.FetchBetween(ctx, from, to)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	statement := &model.Statement{
+		From:           req.From,
+		To:             req.To,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Entries:        make([]*model.StatementEntry, 0, len(transactions)),
+	}
+
+	for _, transaction := range transactions {
+		entry := &model.StatementEntry{
+			TransactionID: transaction.ID,
+			Date:          transaction.CreatedAt,
+			Reference:     transaction.Reference,
+			Description:   transaction.Note,
+			Category:      transaction.Category,
+		}
+
+		if transaction.Type == model.TransactionsTypeCredit {
+			entry.Credit = transaction.Amount
+			statement.TotalCredit += transaction.Amount
+			statement.ClosingBalance += transaction.Amount
+		} else {
+			entry.Debit = transaction.Amount
+			statement.TotalDebit += transaction.Amount
+			statement.ClosingBalance -= transaction.Amount
+		}
+
+		entry.Balance = statement.ClosingBalance
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	return statement, http.StatusOK, nil
+}
+
+func (c *companyUsecase) Reconcile(ctx context.Context) (*model.Reconciliation, int, error) {
+	var reconciliation *model.Reconciliation
+
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		reconciliation, err = c.reconcile(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return reconciliation, http.StatusOK, nil
+}
+
+// AdjustToBalance records the discrepancy between the company balance and the
+// ledger as a reconciliation entry, so the ledger explains the current
+// balance. The company balance itself is left untouched.
+func (c *companyUsecase) AdjustToBalance(ctx context.Context, req request.AdjustmentRequest) (*model.Reconciliation, int, error) {
+	var reconciliation *model.Reconciliation
+
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		reconciliation, err = c.reconcile(ctx)
+		if err != nil {
+			return err
+		}
+
+		if reconciliation.Balanced {
+			return model.ErrLedgerBalanced
+		}
+
+		adjustment := &model.Transaction{
+			Amount:   reconciliation.Discrepancy,
+			Note:     req.Reason,
+			Type:     model.TransactionsTypeCredit,
+			Status:   model.TransactionStatusCompleted,
+			Category: model.TransactionCategoryReconciliation,
+		}
+		if reconciliation.Discrepancy < 0 {
+			adjustment.Amount = -reconciliation.Discrepancy
+			adjustment.Type = model.TransactionTypeDebit
+		}
+
+		reconciliation.Adjustment, err = c.transactionRepo.Create(ctx, adjustment)
+
+		return err
+	})
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	return reconciliation, http.StatusOK, nil
+}
+
+// reconcile compares the company balance with the ledger. The company row is
+// locked so no balance change can land between the two reads.
+func (c *companyUsecase) reconcile(ctx context.Context) (*model.Reconciliation, error) {
+	company, err := c.companyRepo.GetForUpdate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := c.transactionRepo.LedgerBalance(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Reconciliation{
+		CompanyBalance: company.Balance,
+		LedgerBalance:  ledger,
+		Discrepancy:    company.Balance - ledger,
+		Balanced:       company.Balance == ledger,
+		CheckedAt:      time.Now(),
+	}, nil
+}
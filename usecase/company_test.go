@@ -11,11 +11,17 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestGetCompanyInfo(t *testing.T) {
-	var mockRepo mocks.CompanyRepository
-	useCase := NewCompanyUsecase(&mockRepo)
+	var (
+		mockRepo            mocks.CompanyRepository
+		transactionMockRepo mocks.TransactionRepository
+		transactorMock      mocks.Transactor
+	)
+	useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, nil, &transactorMock)
 	ctx := context.Background()
 	companyData := &model.Company{
 		ID:        1,
@@ -63,44 +69,98 @@ func TestGetCompanyInfo(t *testing.T) {
 }
 
 func TestCreateOrUpdateCompany(t *testing.T) {
-	var mockRepo mocks.CompanyRepository
-	useCase := NewCompanyUsecase(&mockRepo)
 	ctx := context.Background()
 	companyData := &model.Company{
-		ID:        1,
-		Name:      "Test Company",
-		Address:   "Cempaka St.",
-		Balance:   200000,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             1,
+		Name:           "Test Company",
+		Address:        "Cempaka St.",
+		Balance:        200000,
+		OverdraftLimit: 50000,
+		PayPeriod:      model.PayPeriodMonthly,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
+	overdraftLimit := 80000
 	tests := []struct {
 		name           string
 		input          request.CompanyRequest
-		data           *model.Company
-		status         int
-		err            error
+		existing       *model.Company
+		getErr         error
+		repoErr        error
 		expectedResp   *model.Company
 		expectedStatus int
 		expectedErr    error
 	}{
 		{
-			name: "should create or update company",
+			name: "should create company with an opening balance",
 			input: request.CompanyRequest{
 				Name:    companyData.Name,
 				Address: companyData.Address,
 				Balance: companyData.Balance,
 			},
-			data:           companyData,
-			status:         http.StatusOK,
+			getErr:         gorm.ErrRecordNotFound,
 			expectedResp:   companyData,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name: "should get some error while create company",
+			input: request.CompanyRequest{
+				Name:    companyData.Name,
+				Address: companyData.Address,
+				Balance: companyData.Balance,
+			},
+			getErr:         gorm.ErrRecordNotFound,
+			repoErr:        errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name: "should update the profile only",
+			input: request.CompanyRequest{
+				Name:    "New Name",
+				Address: "Melati St.",
+			},
+			existing:       companyData,
+			expectedResp:   companyData,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should change the overdraft limit",
+			input: request.CompanyRequest{
+				Name:           "New Name",
+				Address:        "Melati St.",
+				OverdraftLimit: &overdraftLimit,
+			},
+			existing:       companyData,
+			expectedResp:   companyData,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should accept the unchanged balance on update",
+			input: request.CompanyRequest{
+				Name:    "New Name",
+				Address: "Melati St.",
+				Balance: companyData.Balance,
+			},
+			existing:       companyData,
+			expectedResp:   companyData,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "should refuse to rewrite the balance",
+			input: request.CompanyRequest{
+				Name:    companyData.Name,
+				Address: companyData.Address,
+				Balance: 1,
+			},
+			existing:       companyData,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrBalanceReadOnly,
+		},
 		{
 			name:           "should got error unprocessable entity",
 			input:          request.CompanyRequest{},
-			status:         http.StatusUnprocessableEntity,
-			err:            errors.New("some error"),
+			getErr:         errors.New("some error"),
 			expectedStatus: http.StatusUnprocessableEntity,
 			expectedErr:    errors.New("some error"),
 		},
@@ -108,12 +168,46 @@ func TestCreateOrUpdateCompany(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			mockRepo.On("CreateOrUpdate", ctx, &model.Company{
-				Name:    test.input.Name,
-				Address: test.input.Address,
-				Balance: test.input.Balance,
-			}).
-				Return(test.data, test.err).Once()
+			var (
+				mockRepo            mocks.CompanyRepository
+				transactionMockRepo mocks.TransactionRepository
+				transactorMock      mocks.Transactor
+				auditMockRepo       mocks.AuditLogRepository
+			)
+			useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, &auditMockRepo, &transactorMock)
+			profile := &model.Company{
+				Name:      test.input.Name,
+				Address:   test.input.Address,
+				PayPeriod: test.input.PayPeriod,
+			}
+			// the overdraft limit is kept on an update that leaves it out
+			updatedOverdraftLimit := companyData.OverdraftLimit
+			if test.input.OverdraftLimit != nil {
+				profile.OverdraftLimit = *test.input.OverdraftLimit
+				updatedOverdraftLimit = *test.input.OverdraftLimit
+			}
+
+			mockRepo.On("Get", ctx).Return(test.existing, test.getErr).Once()
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("Create", ctx, profile).Return(&model.Company{ID: 1}, test.repoErr).Once()
+			mockRepo.On("AddBalance", ctx, &model.Transaction{
+				Amount:   test.input.Balance,
+				Note:     "Opening balance",
+				Category: model.TransactionCategoryOpeningBalance,
+			}).Return(test.expectedResp, nil).Once()
+			mockRepo.On("UpdateProfile", ctx, companyData.ID, &model.Company{
+				Name:           test.input.Name,
+				Address:        test.input.Address,
+				OverdraftLimit: updatedOverdraftLimit,
+				PayPeriod:      model.PayPeriodMonthly,
+			}).Return(test.expectedResp, test.repoErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				if test.existing == nil {
+					return log.Action == model.AuditActionCreate && log.After["balance"] == float64(test.input.Balance)
+				}
+				return log.Action == model.AuditActionUpdate
+			})).Return(nil, nil).Once()
 			result, status, err := useCase.CreateOrUpdateCompany(ctx, test.input)
 
 			assert.Equal(t, test.expectedResp, result)
@@ -123,9 +217,98 @@ func TestCreateOrUpdateCompany(t *testing.T) {
 	}
 }
 
+func TestAdjustBalance(t *testing.T) {
+	ctx := context.Background()
+	companyData := &model.Company{
+		ID:      1,
+		Name:    "Test Company",
+		Address: "Cempaka St.",
+		Balance: 200000,
+	}
+	tests := []struct {
+		name           string
+		input          request.BalanceAdjustmentRequest
+		addErr         error
+		debitErr       error
+		expectedResp   *model.Company
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:           "should credit a positive amount",
+			input:          request.BalanceAdjustmentRequest{Amount: 50000, Reason: "bank interest"},
+			expectedResp:   companyData,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should debit a negative amount",
+			input:          request.BalanceAdjustmentRequest{Amount: -50000, Reason: "bank fee"},
+			expectedResp:   companyData,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should get some error while credit",
+			input:          request.BalanceAdjustmentRequest{Amount: 50000, Reason: "bank interest"},
+			addErr:         errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get insufficient balance while debit",
+			input:          request.BalanceAdjustmentRequest{Amount: -500000, Reason: "bank fee"},
+			debitErr:       model.ErrInsufficientBalance,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrInsufficientBalance,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo            mocks.CompanyRepository
+				transactionMockRepo mocks.TransactionRepository
+				transactorMock      mocks.Transactor
+				auditMockRepo       mocks.AuditLogRepository
+			)
+			useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, &auditMockRepo, &transactorMock)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("AddBalance", ctx, &model.Transaction{
+				Amount:   test.input.Amount,
+				Note:     test.input.Reason,
+				Category: model.TransactionCategoryAdjustment,
+			}).Return(test.expectedResp, test.addErr).Once()
+			mockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:   -test.input.Amount,
+				Note:     test.input.Reason,
+				Category: model.TransactionCategoryAdjustment,
+			}).Return(test.debitErr).Once()
+			mockRepo.On("Get", ctx).Return(companyData, nil).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Before["balance"] == float64(companyData.Balance-test.input.Amount) &&
+					log.After["balance"] == float64(companyData.Balance)
+			})).Return(nil, nil).Once()
+			result, status, err := useCase.AdjustBalance(ctx, test.input)
+
+			assert.Equal(t, test.expectedResp, result)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				auditMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestTopupBalance(t *testing.T) {
-	var mockRepo mocks.CompanyRepository
-	useCase := NewCompanyUsecase(&mockRepo)
+	var (
+		mockRepo            mocks.CompanyRepository
+		transactionMockRepo mocks.TransactionRepository
+		transactorMock      mocks.Transactor
+		auditMockRepo       mocks.AuditLogRepository
+	)
+	useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, &auditMockRepo, &transactorMock)
 	ctx := context.Background()
 	companyData := &model.Company{
 		ID:        1,
@@ -148,7 +331,8 @@ func TestTopupBalance(t *testing.T) {
 		{
 			name: "should top up balance successfully",
 			input: request.TopupCompanyBalance{
-				Balance: companyData.Balance,
+				Balance:   companyData.Balance,
+				Reference: "TRF-0001",
 			},
 			data:           companyData,
 			status:         http.StatusOK,
@@ -167,8 +351,18 @@ func TestTopupBalance(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			mockRepo.On("AddBalance", ctx, test.input.Balance).
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("AddBalance", ctx, &model.Transaction{
+				Amount:    test.input.Balance,
+				Note:      "Topup balance company",
+				Category:  model.TransactionCategoryTopup,
+				Reference: test.input.Reference,
+			}).
 				Return(test.data, test.err).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Before["balance"] == float64(0) && log.After["balance"] == float64(companyData.Balance)
+			})).Return(nil, nil).Once()
 			result, status, err := useCase.TopupBalance(ctx, test.input)
 
 			assert.Equal(t, test.expectedResp, result)
@@ -177,3 +371,285 @@ func TestTopupBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStatement(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2022, time.October, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2022, time.November, 1, 0, 0, 0, 0, time.Local)
+	transactions := []*model.Transaction{
+		{
+			ID:        1,
+			Amount:    500000,
+			Note:      "Topup balance company",
+			Type:      model.TransactionsTypeCredit,
+			Category:  model.TransactionCategoryTopup,
+			Reference: "TRF-0001",
+			CreatedAt: from.Add(time.Hour),
+		},
+		{
+			ID:        2,
+			Amount:    100000,
+			Note:      "user withdraw salary ",
+			Type:      model.TransactionTypeDebit,
+			Category:  model.TransactionCategorySalaryWithdrawal,
+			Reference: "SAL-2022-10-1",
+			CreatedAt: from.Add(2 * time.Hour),
+		},
+	}
+	tests := []struct {
+		name           string
+		input          request.StatementRequest
+		opening        int
+		openingErr     error
+		data           []*model.Transaction
+		fetchErr       error
+		expectedResp   *model.Statement
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:    "should compute running balance from the ledger",
+			input:   request.StatementRequest{From: "2022-10-01", To: "2022-10-31"},
+			opening: 200000,
+			data:    transactions,
+			expectedResp: &model.Statement{
+				From:           "2022-10-01",
+				To:             "2022-10-31",
+				OpeningBalance: 200000,
+				TotalCredit:    500000,
+				TotalDebit:     100000,
+				ClosingBalance: 600000,
+				Entries: []*model.StatementEntry{
+					{
+						TransactionID: 1,
+						Date:          from.Add(time.Hour),
+						Reference:     "TRF-0001",
+						Description:   "Topup balance company",
+						Category:      model.TransactionCategoryTopup,
+						Credit:        500000,
+						Balance:       700000,
+					},
+					{
+						TransactionID: 2,
+						Date:          from.Add(2 * time.Hour),
+						Reference:     "SAL-2022-10-1",
+						Description:   "user withdraw salary ",
+						Category:      model.TransactionCategorySalaryWithdrawal,
+						Debit:         100000,
+						Balance:       600000,
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should reject a range ending before it starts",
+			input:          request.StatementRequest{From: "2022-10-31", To: "2022-10-01"},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr:    errors.New("to date must not be before from date"),
+		},
+		{
+			name:           "should get some error while sum opening balance",
+			input:          request.StatementRequest{From: "2022-10-01", To: "2022-10-31"},
+			openingErr:     errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get some error while fetch transactions",
+			input:          request.StatementRequest{From: "2022-10-01", To: "2022-10-31"},
+			fetchErr:       errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo            mocks.CompanyRepository
+				transactionMockRepo mocks.TransactionRepository
+				transactorMock      mocks.Transactor
+			)
+			useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, nil, &transactorMock)
+
+			transactionMockRepo.On("LedgerBalance", ctx, from).Return(test.opening, test.openingErr).Once()
+			if test.openingErr == nil {
+				transactionMockRepo.On("FetchBetween", ctx, from, to).Return(test.data, test.fetchErr).Once()
+			}
+			result, status, err := useCase.GetStatement(ctx, test.input)
+
+			assert.Equal(t, test.expectedResp, result)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	companyData := &model.Company{
+		ID:      1,
+		Name:    "Test Company",
+		Address: "Cempaka St.",
+		Balance: 200000,
+	}
+	tests := []struct {
+		name           string
+		companyErr     error
+		ledger         int
+		ledgerErr      error
+		expectedResp   *model.Reconciliation
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:   "should report a balanced ledger",
+			ledger: 200000,
+			expectedResp: &model.Reconciliation{
+				CompanyBalance: 200000,
+				LedgerBalance:  200000,
+				Balanced:       true,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should report the discrepancy",
+			ledger: 150000,
+			expectedResp: &model.Reconciliation{
+				CompanyBalance: 200000,
+				LedgerBalance:  150000,
+				Discrepancy:    50000,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should get some error while get company",
+			companyErr:     errors.New("company data not found"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("company data not found"),
+		},
+		{
+			name:           "should get some error while sum ledger",
+			ledgerErr:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo            mocks.CompanyRepository
+				transactionMockRepo mocks.TransactionRepository
+				transactorMock      mocks.Transactor
+			)
+			useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, nil, &transactorMock)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("GetForUpdate", ctx).Return(companyData, test.companyErr).Once()
+			transactionMockRepo.On("LedgerBalance", ctx, time.Time{}).Return(test.ledger, test.ledgerErr).Once()
+			result, status, err := useCase.Reconcile(ctx)
+
+			if test.expectedResp != nil {
+				test.expectedResp.CheckedAt = result.CheckedAt
+			}
+			assert.Equal(t, test.expectedResp, result)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestAdjustToBalance(t *testing.T) {
+	ctx := context.Background()
+	companyData := &model.Company{
+		ID:      1,
+		Name:    "Test Company",
+		Address: "Cempaka St.",
+		Balance: 200000,
+	}
+	input := request.AdjustmentRequest{Reason: "opening balance set before the ledger existed"}
+	tests := []struct {
+		name               string
+		ledger             int
+		expectedAdjustment *model.Transaction
+		createErr          error
+		expectedStatus     int
+		expectedErr        error
+	}{
+		{
+			name:   "should credit the missing amount",
+			ledger: 150000,
+			expectedAdjustment: &model.Transaction{
+				Amount:   50000,
+				Note:     input.Reason,
+				Type:     model.TransactionsTypeCredit,
+				Status:   model.TransactionStatusCompleted,
+				Category: model.TransactionCategoryReconciliation,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "should debit the surplus amount",
+			ledger: 230000,
+			expectedAdjustment: &model.Transaction{
+				Amount:   30000,
+				Note:     input.Reason,
+				Type:     model.TransactionTypeDebit,
+				Status:   model.TransactionStatusCompleted,
+				Category: model.TransactionCategoryReconciliation,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should refuse when already balanced",
+			ledger:         200000,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrLedgerBalanced,
+		},
+		{
+			name:   "should get some error while create adjustment",
+			ledger: 150000,
+			expectedAdjustment: &model.Transaction{
+				Amount:   50000,
+				Note:     input.Reason,
+				Type:     model.TransactionsTypeCredit,
+				Status:   model.TransactionStatusCompleted,
+				Category: model.TransactionCategoryReconciliation,
+			},
+			createErr:      errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo            mocks.CompanyRepository
+				transactionMockRepo mocks.TransactionRepository
+				transactorMock      mocks.Transactor
+			)
+			useCase := NewCompanyUsecase(&mockRepo, &transactionMockRepo, nil, &transactorMock)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("GetForUpdate", ctx).Return(companyData, nil).Once()
+			transactionMockRepo.On("LedgerBalance", ctx, time.Time{}).Return(test.ledger, nil).Once()
+			transactionMockRepo.On("Create", ctx, test.expectedAdjustment).
+				Return(test.expectedAdjustment, test.createErr).Once()
+			result, status, err := useCase.AdjustToBalance(ctx, input)
+
+			if test.expectedErr == nil {
+				assert.Equal(t, test.expectedAdjustment, result.Adjustment)
+				assert.Equal(t, companyData.Balance, result.CompanyBalance)
+			} else {
+				assert.Nil(t, result)
+			}
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
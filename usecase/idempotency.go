@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"self-payrol/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type idempotencyUsecase struct {
+	idempotencyRepo model.IdempotencyRepository
+	retention       time.Duration
+}
+
+func NewIdempotencyUsecase(idempotency model.IdempotencyRepository, retention time.Duration) model.IdempotencyUsecase {
+	return &idempotencyUsecase{idempotencyRepo: idempotency, retention: retention}
+}
+
+// Begin claims key within scope for a request whose body hashes to
+// requestHash. A completed key is returned as is so its response can be
+// replayed. Keys older than the retention window are forgotten.
+func (i *idempotencyUsecase) Begin(ctx context.Context, key, scope, requestHash string) (*model.IdempotencyKey, error) {
+	existing, err := i.idempotencyRepo.Find(ctx, key, scope)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if existing != nil && time.Now().After(existing.ExpiresAt) {
+		if err := i.idempotencyRepo.Delete(ctx, existing.ID); err != nil {
+			return nil, err
+		}
+		existing = nil
+	}
+
+	if existing != nil {
+		if existing.RequestHash != requestHash {
+			return nil, model.ErrIdempotencyKeyReused
+		}
+
+		if !existing.Completed() {
+			return nil, model.ErrIdempotencyInProgress
+		}
+
+		return existing, nil
+	}
+
+	return i.idempotencyRepo.Create(ctx, &model.IdempotencyKey{
+		Key:         key,
+		Scope:       scope,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(i.retention),
+	})
+}
+
+func (i *idempotencyUsecase) Complete(ctx context.Context, key *model.IdempotencyKey, statusCode int, response []byte) error {
+	if err := i.idempotencyRepo.Complete(ctx, key.ID, statusCode, response); err != nil {
+		return err
+	}
+
+	key.StatusCode = statusCode
+	key.Response = response
+
+	return nil
+}
+
+// Release forgets key so the request can be retried, used when handling it
+// failed before a final response was produced.
+func (i *idempotencyUsecase) Release(ctx context.Context, key *model.IdempotencyKey) error {
+	return i.idempotencyRepo.Delete(ctx, key.ID)
+}
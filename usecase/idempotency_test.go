@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"self-payrol/model"
+	"self-payrol/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestBeginIdempotency(t *testing.T) {
+	ctx := context.Background()
+	completed := &model.IdempotencyKey{
+		ID:          1,
+		Key:         "f1c9a3e2",
+		Scope:       "POST /company/topup",
+		RequestHash: "hash",
+		StatusCode:  200,
+		Response:    []byte(`{"success":true}`),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	inProgress := &model.IdempotencyKey{
+		ID:          1,
+		Key:         "f1c9a3e2",
+		Scope:       "POST /company/topup",
+		RequestHash: "hash",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	expired := &model.IdempotencyKey{
+		ID:          1,
+		Key:         "f1c9a3e2",
+		Scope:       "POST /company/topup",
+		RequestHash: "other hash",
+		StatusCode:  200,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}
+	created := &model.IdempotencyKey{
+		ID:          2,
+		Key:         "f1c9a3e2",
+		Scope:       "POST /company/topup",
+		RequestHash: "hash",
+	}
+	tests := []struct {
+		name         string
+		hash         string
+		existing     *model.IdempotencyKey
+		findErr      error
+		deleteErr    error
+		createErr    error
+		expectedResp *model.IdempotencyKey
+		expectedErr  error
+	}{
+		{
+			name:         "should claim a new key",
+			hash:         "hash",
+			findErr:      gorm.ErrRecordNotFound,
+			expectedResp: created,
+		},
+		{
+			name:         "should return the completed key for the same request",
+			hash:         "hash",
+			existing:     completed,
+			expectedResp: completed,
+		},
+		{
+			name:        "should refuse the key reused with another request",
+			hash:        "another hash",
+			existing:    completed,
+			expectedErr: model.ErrIdempotencyKeyReused,
+		},
+		{
+			name:        "should refuse the key while the first request is running",
+			hash:        "hash",
+			existing:    inProgress,
+			expectedErr: model.ErrIdempotencyInProgress,
+		},
+		{
+			name:         "should claim an expired key again",
+			hash:         "hash",
+			existing:     expired,
+			expectedResp: created,
+		},
+		{
+			name:        "should get some error while delete expired key",
+			hash:        "hash",
+			existing:    expired,
+			deleteErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name:        "should get some error while find key",
+			hash:        "hash",
+			findErr:     errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name:        "should get in progress error when losing the race to create",
+			hash:        "hash",
+			findErr:     gorm.ErrRecordNotFound,
+			createErr:   model.ErrIdempotencyInProgress,
+			expectedErr: model.ErrIdempotencyInProgress,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mockRepo mocks.IdempotencyRepository
+			useCase := NewIdempotencyUsecase(&mockRepo, time.Hour)
+
+			mockRepo.On("Find", ctx, "f1c9a3e2", "POST /company/topup").Return(test.existing, test.findErr).Once()
+			mockRepo.On("Delete", ctx, 1).Return(test.deleteErr).Once()
+			mockRepo.On("Create", ctx, mock.MatchedBy(func(key *model.IdempotencyKey) bool {
+				return key.Key == "f1c9a3e2" && key.Scope == "POST /company/topup" &&
+					key.RequestHash == test.hash && key.ExpiresAt.After(time.Now())
+			})).Return(func(ctx context.Context, key *model.IdempotencyKey) *model.IdempotencyKey {
+				if test.createErr != nil {
+					return nil
+				}
+				return created
+			}, test.createErr).Once()
+			res, err := useCase.Begin(ctx, "f1c9a3e2", "POST /company/topup", test.hash)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestCompleteIdempotency(t *testing.T) {
+	var mockRepo mocks.IdempotencyRepository
+	useCase := NewIdempotencyUsecase(&mockRepo, time.Hour)
+	ctx := context.Background()
+	response := []byte(`{"success":true}`)
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{
+			name: "should store the response",
+		},
+		{
+			name:        "should get some error",
+			err:         errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key := &model.IdempotencyKey{ID: 1, Key: "f1c9a3e2"}
+			mockRepo.On("Complete", ctx, 1, 200, response).Return(test.err).Once()
+			err := useCase.Complete(ctx, key, 200, response)
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.err == nil, key.Completed())
+		})
+	}
+}
+
+func TestReleaseIdempotency(t *testing.T) {
+	var mockRepo mocks.IdempotencyRepository
+	useCase := NewIdempotencyUsecase(&mockRepo, time.Hour)
+	ctx := context.Background()
+
+	mockRepo.On("Delete", ctx, 1).Return(nil).Once()
+
+	assert.NoError(t, useCase.Release(ctx, &model.IdempotencyKey{ID: 1}))
+}
@@ -16,13 +16,13 @@ type CompanyRepository struct {
 	mock.Mock
 }
 
-// AddBalance provides a mock function with given fields: ctx, balance
-func (_m *CompanyRepository) AddBalance(ctx context.Context, balance int) (*model.Company, error) {
-	ret := _m.Called(ctx, balance)
+// AddBalance provides a mock function with given fields: ctx, transaction
+func (_m *CompanyRepository) AddBalance(ctx context.Context, transaction *model.Transaction) (*model.Company, error) {
+	ret := _m.Called(ctx, transaction)
 
 	var r0 *model.Company
-	if rf, ok := ret.Get(0).(func(context.Context, int) *model.Company); ok {
-		r0 = rf(ctx, balance)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Transaction) *model.Company); ok {
+		r0 = rf(ctx, transaction)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Company)
@@ -30,8 +30,8 @@ func (_m *CompanyRepository) AddBalance(ctx context.Context, balance int) (*mode
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, balance)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Transaction) error); ok {
+		r1 = rf(ctx, transaction)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -39,13 +39,13 @@ func (_m *CompanyRepository) AddBalance(ctx context.Context, balance int) (*mode
 	return r0, r1
 }
 
-// CreateOrUpdate provides a mock function with given fields: ctx, Company
-func (_m *CompanyRepository) CreateOrUpdate(ctx context.Context, Company *model.Company) (*model.Company, error) {
-	ret := _m.Called(ctx, Company)
+// Create provides a mock function with given fields: ctx, company
+func (_m *CompanyRepository) Create(ctx context.Context, company *model.Company) (*model.Company, error) {
+	ret := _m.Called(ctx, company)
 
 	var r0 *model.Company
 	if rf, ok := ret.Get(0).(func(context.Context, *model.Company) *model.Company); ok {
-		r0 = rf(ctx, Company)
+		r0 = rf(ctx, company)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Company)
@@ -54,7 +54,7 @@ func (_m *CompanyRepository) CreateOrUpdate(ctx context.Context, Company *model.
 
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, *model.Company) error); ok {
-		r1 = rf(ctx, Company)
+		r1 = rf(ctx, company)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -62,13 +62,13 @@ func (_m *CompanyRepository) CreateOrUpdate(ctx context.Context, Company *model.
 	return r0, r1
 }
 
-// DebitBalance provides a mock function with given fields: ctx, amount, note
-func (_m *CompanyRepository) DebitBalance(ctx context.Context, amount int, note string) error {
-	ret := _m.Called(ctx, amount, note)
+// DebitBalance provides a mock function with given fields: ctx, transaction
+func (_m *CompanyRepository) DebitBalance(ctx context.Context, transaction *model.Transaction) error {
+	ret := _m.Called(ctx, transaction)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
-		r0 = rf(ctx, amount, note)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Transaction) error); ok {
+		r0 = rf(ctx, transaction)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -99,6 +99,52 @@ func (_m *CompanyRepository) Get(ctx context.Context) (*model.Company, error) {
 	return r0, r1
 }
 
+// GetForUpdate provides a mock function with given fields: ctx
+func (_m *CompanyRepository) GetForUpdate(ctx context.Context) (*model.Company, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *model.Company
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Company); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Company)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, id, company
+func (_m *CompanyRepository) UpdateProfile(ctx context.Context, id int, company *model.Company) (*model.Company, error) {
+	ret := _m.Called(ctx, id, company)
+
+	var r0 *model.Company
+	if rf, ok := ret.Get(0).(func(context.Context, int, *model.Company) *model.Company); ok {
+		r0 = rf(ctx, id, company)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Company)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, *model.Company) error); ok {
+		r1 = rf(ctx, id, company)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewCompanyRepository creates a new instance of CompanyRepository. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewCompanyRepository(t testing.TB) *CompanyRepository {
 	mock := &CompanyRepository{}
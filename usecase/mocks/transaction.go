@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 	model "self-payrol/model"
+	request "self-payrol/request"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -16,13 +18,103 @@ type TransactionRepository struct {
 	mock.Mock
 }
 
-// Fetch provides a mock function with given fields: ctx, limit, offset
-func (_m *TransactionRepository) Fetch(ctx context.Context, limit int, offset int) ([]*model.Transaction, error) {
-	ret := _m.Called(ctx, limit, offset)
+// Count provides a mock function with given fields: ctx, filter
+func (_m *TransactionRepository) Count(ctx context.Context, filter request.TransactionFilter) (int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, request.TransactionFilter) int64); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, request.TransactionFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, transaction
+func (_m *TransactionRepository) Create(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+	ret := _m.Called(ctx, transaction)
+
+	var r0 *model.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Transaction) *model.Transaction); ok {
+		r0 = rf(ctx, transaction)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Transaction) error); ok {
+		r1 = rf(ctx, transaction)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fetch provides a mock function with given fields: ctx, filter
+func (_m *TransactionRepository) Fetch(ctx context.Context, filter request.TransactionFilter) ([]*model.Transaction, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*model.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, request.TransactionFilter) []*model.Transaction); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, request.TransactionFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchBetween provides a mock function with given fields: ctx, from, to
+func (_m *TransactionRepository) FetchBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.Transaction, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []*model.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []*model.Transaction); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchUnreversed provides a mock function with given fields: ctx, references
+func (_m *TransactionRepository) FetchUnreversed(ctx context.Context, references []string) ([]*model.Transaction, error) {
+	ret := _m.Called(ctx, references)
 
 	var r0 []*model.Transaction
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*model.Transaction); ok {
-		r0 = rf(ctx, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.Transaction); ok {
+		r0 = rf(ctx, references)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.Transaction)
@@ -30,8 +122,8 @@ func (_m *TransactionRepository) Fetch(ctx context.Context, limit int, offset in
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = rf(ctx, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, references)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -39,6 +131,64 @@ func (_m *TransactionRepository) Fetch(ctx context.Context, limit int, offset in
 	return r0, r1
 }
 
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *TransactionRepository) FindByID(ctx context.Context, id int) (*model.Transaction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *model.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int) *model.Transaction); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LedgerBalance provides a mock function with given fields: ctx, until
+func (_m *TransactionRepository) LedgerBalance(ctx context.Context, until time.Time) (int, error) {
+	ret := _m.Called(ctx, until)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, until)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkReversed provides a mock function with given fields: ctx, id, reversedAt
+func (_m *TransactionRepository) MarkReversed(ctx context.Context, id int, reversedAt time.Time) error {
+	ret := _m.Called(ctx, id, reversedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, id, reversedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewTransactionRepository creates a new instance of TransactionRepository. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewTransactionRepository(t testing.TB) *TransactionRepository {
 	mock := &TransactionRepository{}
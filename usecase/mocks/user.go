@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	model "self-payrol/model"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -99,6 +100,48 @@ func (_m *UserRepository) FindByID(ctx context.Context, id int) (*model.User, er
 	return r0, r1
 }
 
+// RegisterFailedSecret provides a mock function with given fields: ctx, id, maxAttempts, lockedUntil
+func (_m *UserRepository) RegisterFailedSecret(ctx context.Context, id int, maxAttempts int, lockedUntil time.Time) error {
+	ret := _m.Called(ctx, id, maxAttempts, lockedUntil)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, time.Time) error); ok {
+		r0 = rf(ctx, id, maxAttempts, lockedUntil)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetFailedSecrets provides a mock function with given fields: ctx, id
+func (_m *UserRepository) ResetFailedSecrets(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateBaseSalary provides a mock function with given fields: ctx, id, baseSalary
+func (_m *UserRepository) UpdateBaseSalary(ctx context.Context, id int, baseSalary *int) error {
+	ret := _m.Called(ctx, id, baseSalary)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int) error); ok {
+		r0 = rf(ctx, id, baseSalary)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateByID provides a mock function with given fields: ctx, id, user
 func (_m *UserRepository) UpdateByID(ctx context.Context, id int, user *model.User) (*model.User, error) {
 	ret := _m.Called(ctx, id, user)
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"self-payrol/model"
+	"strings"
+	"time"
+)
+
+// payroll works out and pays salaries, for the withdrawals of employees and
+// for payroll runs alike.
+type payroll struct {
+	salaryRepo     model.SalaryVersionRepository
+	componentRepo  model.PayComponentRepository
+	companyRepo    model.CompanyRepository
+	withdrawalRepo model.WithdrawalRepository
+	payslipRepo    model.PayslipRepository
+	taxCalculator  model.TaxCalculator
+	contributions  []model.ContributionRule
+}
+
+// salaryEntry is the ledger entry paying user the net pay of pay for period.
+func salaryEntry(user *model.User, period model.PayPeriod, pay *model.PayBreakdown, note string) *model.Transaction {
+	return &model.Transaction{
+		Amount:     pay.NetPay,
+		Note:       note,
+		Category:   model.TransactionCategorySalaryWithdrawal,
+		Reference:  fmt.Sprintf("SAL-%s-%d", period.Key, user.ID),
+		UserID:     &user.ID,
+		PositionID: &user.PositionID,
+	}
+}
+
+// recordPayout books the salary of user for period, paid with the debited
+// ledger entry transaction: it keeps the withdrawal and its payslip and debits
+// the tax and contributions withheld. A debit of those the balance cannot
+// cover is reported, so the caller rolls the whole payout back.
+func (p *payroll) recordPayout(ctx context.Context, user *model.User, company *model.Company, period model.PayPeriod,
+	pay *model.PayBreakdown, transaction *model.Transaction) error {
+	withdrawal := withdrawalOf(user, period, pay)
+	withdrawal.TransactionID = transaction.ID
+
+	_, err := p.withdrawalRepo.Create(ctx, withdrawal)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.payslipRepo.Create(ctx, model.NewPayslip(user, company, period, pay, transaction.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range withheldEntries(user, period, pay) {
+		if err := p.companyRepo.DebitBalance(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withdrawalOf is the withdrawal paying user pay for period.
+func withdrawalOf(user *model.User, period model.PayPeriod, pay *model.PayBreakdown) *model.Withdrawal {
+	return &model.Withdrawal{
+		UserID:                  user.ID,
+		Period:                  period.Key,
+		PeriodStart:             period.Start,
+		PeriodEnd:               period.End,
+		Amount:                  pay.NetPay,
+		GrossPay:                pay.GrossPay,
+		Tax:                     pay.Tax,
+		TaxableBenefits:         model.TaxableBenefits(pay.Contributions),
+		DeductibleContributions: model.DeductibleContributions(pay.Contributions),
+	}
+}
+
+// payFor works out the net pay of user for period, paid at frequency, from
+// their base salary, pay components, contributions and the income tax to
+// withhold. The contributions are worked out first, as some of them count
+// towards the income tax. The salary is the one in effect at the cut-off of
+// the period, whichever day it is withdrawn on.
+func (p *payroll) payFor(ctx context.Context, user *model.User, frequency string, period model.PayPeriod) (*model.PayBreakdown, error) {
+	return p.pay(ctx, user, frequency, period, nil)
+}
+
+// projectPay works out the pay of user for period, paid at frequency, ahead of
+// time: at the salary in effect at the cut-off of the period, scheduled changes
+// included, and taxed as if the projected withdrawals had been paid as well.
+func (p *payroll) projectPay(ctx context.Context, user *model.User, frequency string, period model.PayPeriod,
+	projected []*model.Withdrawal) (*model.PayBreakdown, error) {
+	return p.pay(ctx, user, frequency, period, projected)
+}
+
+// pay works out the pay of user for period at the salary in effect at its
+// cut-off, taxing it against the salaries paid earlier in the tax year and the
+// projected ones.
+func (p *payroll) pay(ctx context.Context, user *model.User, frequency string, period model.PayPeriod,
+	projected []*model.Withdrawal) (*model.PayBreakdown, error) {
+	salary, err := p.salaryFor(ctx, user, period.SalaryCutoff())
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := p.componentRepo.FetchByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pay := model.NewPayBreakdown(salary, components)
+	pay.Contribute(p.contributions, frequency)
+
+	earlier, err := p.withdrawalRepo.FetchByUserBetween(ctx, user.ID, period.TaxYearStart(), period.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, withdrawal := range projected {
+		if !withdrawal.PeriodStart.Before(period.TaxYearStart()) && withdrawal.PeriodStart.Before(period.Start) {
+			earlier = append(earlier, withdrawal)
+		}
+	}
+
+	salaryToTax := model.TaxableSalary{
+		TaxStatus:     user.TaxStatus,
+		Frequency:     frequency,
+		Period:        period,
+		GrossPay:      pay.GrossPay,
+		Contributions: pay.Contributions,
+	}
+	for _, withdrawal := range earlier {
+		salaryToTax.YearGrossPay += withdrawal.GrossPay
+		salaryToTax.YearTaxableBenefits += withdrawal.TaxableBenefits
+		salaryToTax.YearDeductibleContributions += withdrawal.DeductibleContributions
+		salaryToTax.YearWithheld += withdrawal.Tax
+	}
+
+	pay.Withhold(p.taxCalculator.Withhold(salaryToTax))
+
+	return pay, nil
+}
+
+// withheldReferences are the references of the entries withheldEntries books
+// next to the salary entry with reference.
+func withheldReferences(reference string) []string {
+	key := strings.TrimPrefix(reference, "SAL-")
+
+	return []string{"TAX-" + key, "BPJS-EE-" + key, "BPJS-ER-" + key}
+}
+
+// withheldEntries are the ledger entries paid out for user next to their
+// salary for period: the income tax withheld and the contributions of the
+// employee and of the company.
+func withheldEntries(user *model.User, period model.PayPeriod, pay *model.PayBreakdown) []*model.Transaction {
+	entries := []struct {
+		amount    int
+		note      string
+		category  string
+		reference string
+	}{
+		{pay.Tax, " income tax withheld", model.TransactionCategoryTax, "TAX"},
+		{pay.EmployeeContributions, " employee contributions", model.TransactionCategoryEmployeeContribution, "BPJS-EE"},
+		{pay.EmployerContributions, " employer contributions", model.TransactionCategoryEmployerContribution, "BPJS-ER"},
+	}
+
+	var transactions []*model.Transaction
+	for _, entry := range entries {
+		if entry.amount == 0 {
+			continue
+		}
+
+		transactions = append(transactions, &model.Transaction{
+			Amount:     entry.amount,
+			Note:       user.Name + entry.note,
+			Category:   entry.category,
+			Reference:  fmt.Sprintf("%s-%s-%d", entry.reference, period.Key, user.ID),
+			UserID:     &user.ID,
+			PositionID: &user.PositionID,
+		})
+	}
+
+	return transactions
+}
+
+// salaryFor resolves the base salary of user paid until. An override set on
+// the employee wins, otherwise it is the salary of their position in effect
+// before until. Positions without a salary history fall back to their current
+// salary.
+func (p *payroll) salaryFor(ctx context.Context, user *model.User, until time.Time) (int, error) {
+	if user.BaseSalary != nil {
+		return *user.BaseSalary, nil
+	}
+
+	version, err := p.salaryRepo.FindEffective(ctx, user.PositionID, until)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if user.Position == nil {
+			return 0, nil
+		}
+
+		return user.Position.Salary, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return version.Salary, nil
+}
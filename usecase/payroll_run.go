@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"self-payrol/model"
+	"self-payrol/request"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultForecastPeriods is how many pay periods a forecast covers unless
+// asked otherwise.
+const defaultForecastPeriods = 3
+
+type payrollRunUsecase struct {
+	*payroll
+	runRepo      model.PayrollRunRepository
+	userRepo     model.UserRepository
+	auditLogRepo model.AuditLogRepository
+	transactor   model.Transactor
+}
+
+func NewPayrollRunUsecase(run model.PayrollRunRepository, user model.UserRepository, salary model.SalaryVersionRepository,
+	component model.PayComponentRepository, company model.CompanyRepository, withdrawal model.WithdrawalRepository,
+	payslip model.PayslipRepository, auditLog model.AuditLogRepository, transactor model.Transactor,
+	taxCalculator model.TaxCalculator, contributions []model.ContributionRule) model.PayrollRunUsecase {
+	return &payrollRunUsecase{
+		payroll: &payroll{
+			salaryRepo:     salary,
+			componentRepo:  component,
+			companyRepo:    company,
+			withdrawalRepo: withdrawal,
+			payslipRepo:    payslip,
+			taxCalculator:  taxCalculator,
+			contributions:  contributions,
+		},
+		runRepo:      run,
+		userRepo:     user,
+		auditLogRepo: auditLog,
+		transactor:   transactor,
+	}
+}
+
+// Create drafts the payroll run of the pay period req.Date falls in, working
+// out the pay of every employee who has not been paid for it yet. Employees
+// whose deductions leave nothing to pay are left out, as their withdrawals
+// would be refused.
+func (r *payrollRunUsecase) Create(ctx context.Context, req request.PayrollRunRequest) (*model.PayrollRun, int, error) {
+	company, err := r.companyRepo.Get(ctx)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	now := time.Now()
+	at := now
+	if req.Date != "" {
+		at, err = time.ParseInLocation("2006-01-02", req.Date, now.Location())
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	if at.After(now) {
+		return nil, http.StatusUnprocessableEntity, model.ErrFuturePayPeriod
+	}
+
+	period := model.NewPayPeriod(company.PayPeriod, at)
+
+	// a zero limit lists every employee
+	users, err := r.userRepo.Fetch(ctx, 0, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	run := &model.PayrollRun{
+		Period:      period.Key,
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+		Status:      model.PayrollRunDraft,
+		CreatedBy:   model.ActorFromContext(ctx),
+	}
+
+	for _, user := range users {
+		_, err := r.withdrawalRepo.FindOverlapping(ctx, user.ID, period)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		pay, err := r.payFor(ctx, user, company.PayPeriod, period)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		if pay.NetPay <= 0 {
+			continue
+		}
+
+		run.Add(user, pay)
+	}
+
+	if len(run.Items) == 0 {
+		return nil, http.StatusUnprocessableEntity, model.ErrPayrollRunEmpty
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.runRepo.Create(ctx, run); err != nil {
+			return err
+		}
+
+		return audit(ctx, r.auditLogRepo, model.AuditActionCreate, model.AuditEntityPayrollRun, run.ID, nil, run)
+	})
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	return run, http.StatusOK, nil
+}
+
+func (r *payrollRunUsecase) Fetch(ctx context.Context, limit, offset int) ([]*model.PayrollRun, int, error) {
+	runs, err := r.runRepo.Fetch(ctx, limit, offset)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return runs, http.StatusOK, nil
+}
+
+func (r *payrollRunUsecase) GetByID(ctx context.Context, id int) (*model.PayrollRun, int, error) {
+	run, err := r.runRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	return run, http.StatusOK, nil
+}
+
+// Approve signs the draft run with id off for execution.
+func (r *payrollRunUsecase) Approve(ctx context.Context, id int) (*model.PayrollRun, int, error) {
+	return r.transition(ctx, id, func(ctx context.Context, run *model.PayrollRun) error {
+		now := time.Now()
+
+		run.Status = model.PayrollRunApproved
+		run.ApprovedBy = model.ActorFromContext(ctx)
+		run.ApprovedAt = &now
+
+		return nil
+	}, model.PayrollRunDraft)
+}
+
+// Execute pays every employee of the approved run with id the pay it was
+// approved with, all in one database transaction. Each payout is booked like a
+// withdrawal, with its own ledger entries and payslip. The run fails as a
+// whole, paying no one, when the balance cannot cover it or an employee has
+// been paid for the period since it was drafted.
+func (r *payrollRunUsecase) Execute(ctx context.Context, id int) (*model.PayrollRun, int, error) {
+	return r.transition(ctx, id, func(ctx context.Context, run *model.PayrollRun) error {
+		company, err := r.companyRepo.GetForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !company.CanDebit(run.TotalCost) {
+			return model.ErrInsufficientBalance
+		}
+
+		period := run.PayPeriod()
+
+		for _, item := range run.Items {
+			user, err := r.userRepo.FindByID(ctx, item.UserID)
+			if err != nil {
+				return err
+			}
+
+			_, err = r.withdrawalRepo.FindOverlapping(ctx, user.ID, period)
+			if err == nil {
+				return model.ErrAlreadyWithdrawn
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			pay := item.Pay
+			transaction := salaryEntry(user, period, &pay, fmt.Sprintf("%s payroll run %d", user.Name, run.ID))
+
+			if err := r.companyRepo.DebitBalance(ctx, transaction); err != nil {
+				return err
+			}
+
+			if err := r.recordPayout(ctx, user, company, period, &pay, transaction); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+
+		run.Status = model.PayrollRunExecuted
+		run.ExecutedAt = &now
+
+		return nil
+	}, model.PayrollRunApproved)
+}
+
+// Cancel drops the run with id before it is executed.
+func (r *payrollRunUsecase) Cancel(ctx context.Context, id int) (*model.PayrollRun, int, error) {
+	return r.transition(ctx, id, func(ctx context.Context, run *model.PayrollRun) error {
+		now := time.Now()
+
+		run.Status = model.PayrollRunCancelled
+		run.CancelledAt = &now
+
+		return nil
+	}, model.PayrollRunDraft, model.PayrollRunApproved)
+}
+
+// Forecast projects the payroll of req.Periods pay periods, starting with the
+// current one, against the company balance. The pay of every employee is
+// worked out for each period on its own, with the salary changes scheduled by
+// then and the tax settled at the end of the year, as if they were paid every
+// period before. Employees already paid for the current period are only
+// expected from the next one on. The pay of a period falls due when it
+// starts, or now for the current one.
+func (r *payrollRunUsecase) Forecast(ctx context.Context, req request.ForecastRequest) (*model.Forecast, int, error) {
+	company, err := r.companyRepo.Get(ctx)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	periods := req.Periods
+	if periods == 0 {
+		periods = defaultForecastPeriods
+	}
+
+	// a zero limit lists every employee
+	users, err := r.userRepo.Fetch(ctx, 0, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	now := time.Now()
+	period := model.NewPayPeriod(company.PayPeriod, now)
+	dueAt := now
+
+	// the withdrawals each employee is expected to make before the period
+	// forecast, which their tax is worked out against
+	projected := make(map[int][]*model.Withdrawal, len(users))
+
+	forecast := model.NewForecast(company)
+
+	for i := 0; i < periods; i++ {
+		var employees, cost int
+
+		for _, user := range users {
+			if i == 0 {
+				_, err := r.withdrawalRepo.FindOverlapping(ctx, user.ID, period)
+				if err == nil {
+					continue
+				}
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, http.StatusInternalServerError, err
+				}
+			}
+
+			pay, err := r.projectPay(ctx, user, company.PayPeriod, period, projected[user.ID])
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+
+			if pay.NetPay <= 0 {
+				continue
+			}
+
+			employees++
+			cost += pay.Cost()
+			projected[user.ID] = append(projected[user.ID], withdrawalOf(user, period, pay))
+		}
+
+		forecast.Add(period, dueAt, employees, cost)
+
+		period = model.NewPayPeriod(company.PayPeriod, period.End)
+		dueAt = period.Start
+	}
+
+	return forecast, http.StatusOK, nil
+}
+
+// transition moves the run with id, which must be in one of the statuses
+// from, on with move and audits the change, in one database transaction.
+func (r *payrollRunUsecase) transition(ctx context.Context, id int, move func(ctx context.Context, run *model.PayrollRun) error,
+	from ...string) (*model.PayrollRun, int, error) {
+	var run *model.PayrollRun
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := r.runRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !existing.InStatus(from...) {
+			return model.ErrPayrollRunStatus
+		}
+
+		moved := *existing
+		run = &moved
+
+		if err := move(ctx, run); err != nil {
+			return err
+		}
+
+		if err := r.runRepo.UpdateStatus(ctx, run, existing.Status); err != nil {
+			return err
+		}
+
+		return audit(ctx, r.auditLogRepo, model.AuditActionUpdate, model.AuditEntityPayrollRun, id, existing, run)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusNotFound, err
+	}
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	return run, http.StatusOK, nil
+}
@@ -0,0 +1,581 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreatePayrollRun(t *testing.T) {
+	ctx := context.Background()
+	companyData := &model.Company{ID: 1, Name: "Test Company", Balance: 500000, PayPeriod: model.PayPeriodMonthly}
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	users := []*model.User{
+		{ID: 1, Name: "first", PositionID: 1},
+		{ID: 2, Name: "second", PositionID: 1},
+	}
+	draft := func(paid ...*model.User) *model.PayrollRun {
+		run := &model.PayrollRun{
+			Period:      period.Key,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+			Status:      model.PayrollRunDraft,
+			CreatedBy:   model.AuditActorSystem,
+		}
+		for _, user := range paid {
+			run.Add(user, withheld(model.NewPayBreakdown(120000, nil)))
+		}
+		return run
+	}
+	tests := []struct {
+		name           string
+		req            request.PayrollRunRequest
+		companyErr     error
+		usersErr       error
+		secondPaid     bool
+		firstNoNetPay  bool
+		createErr      error
+		expectedRun    *model.PayrollRun
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:           "should draft the pay of every employee",
+			expectedRun:    draft(users...),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should draft the run of an earlier pay period",
+			req:            request.PayrollRunRequest{Date: period.Start.Format("2006-01-02")},
+			expectedRun:    draft(users...),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should leave out employees paid for the period",
+			secondPaid:     true,
+			expectedRun:    draft(users[0]),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should get empty run error when no one is left to pay",
+			secondPaid:     true,
+			firstNoNetPay:  true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrPayrollRunEmpty,
+		},
+		{
+			name:           "should get future pay period error",
+			req:            request.PayrollRunRequest{Date: period.End.AddDate(0, 1, 0).Format("2006-01-02")},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrFuturePayPeriod,
+		},
+		{
+			name:           "should get some error while get company",
+			companyErr:     gorm.ErrRecordNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    gorm.ErrRecordNotFound,
+		},
+		{
+			name:           "should get some error while fetch employees",
+			usersErr:       errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get some error while create run",
+			createErr:      errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				runMockRepo        mocks.PayrollRunRepository
+				userMockRepo       mocks.UserRepository
+				salaryMockRepo     mocks.SalaryVersionRepository
+				componentMockRepo  mocks.PayComponentRepository
+				companyMockRepo    mocks.CompanyRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+				auditLogMockRepo   mocks.AuditLogRepository
+				transactorMock     mocks.Transactor
+			)
+			useCase := NewPayrollRunUsecase(&runMockRepo, &userMockRepo, &salaryMockRepo, &componentMockRepo,
+				&companyMockRepo, &withdrawalMockRepo, nil, &auditLogMockRepo, &transactorMock, flatTax{}, contributionRules)
+
+			companyMockRepo.On("Get", ctx).Return(companyData, test.companyErr).Once()
+			userMockRepo.On("Fetch", ctx, 0, 0).Return(users, test.usersErr).Once()
+			withdrawalMockRepo.On("FindOverlapping", ctx, 1, period).
+				Return(nil, gorm.ErrRecordNotFound).Once()
+			if test.secondPaid {
+				withdrawalMockRepo.On("FindOverlapping", ctx, 2, period).Return(&model.Withdrawal{}, nil).Once()
+			} else {
+				withdrawalMockRepo.On("FindOverlapping", ctx, 2, period).
+					Return(nil, gorm.ErrRecordNotFound).Once()
+			}
+			salaryMockRepo.On("FindEffective", ctx, 1, mock.AnythingOfType("time.Time")).
+				Return(&model.SalaryVersion{Salary: 120000}, nil)
+			var components []*model.PayComponent
+			if test.firstNoNetPay {
+				components = []*model.PayComponent{{Name: "Loan", Type: model.PayComponentDeduction, Amount: 200000}}
+			}
+			componentMockRepo.On("FetchByUser", ctx, 1).Return(components, nil).Once()
+			componentMockRepo.On("FetchByUser", ctx, 2).Return(nil, nil).Once()
+			withdrawalMockRepo.On("FetchByUserBetween", ctx, mock.Anything, period.TaxYearStart(), period.Start).
+				Return([]*model.Withdrawal{}, nil)
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			runMockRepo.On("Create", ctx, mock.AnythingOfType("*model.PayrollRun")).
+				Return(func(ctx context.Context, run *model.PayrollRun) *model.PayrollRun { return run }, test.createErr).Once()
+			auditLogMockRepo.On("Create", ctx, mock.AnythingOfType("*model.AuditLog")).Return(&model.AuditLog{}, nil).Once()
+
+			run, status, err := useCase.Create(ctx, test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedStatus, status)
+			if test.expectedErr == nil {
+				assert.Equal(t, test.expectedRun, run)
+			} else {
+				assert.Nil(t, run)
+			}
+		})
+	}
+}
+
+func TestApprovePayrollRun(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name           string
+		status         string
+		findErr        error
+		updateErr      error
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:           "should approve a draft run",
+			status:         model.PayrollRunDraft,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should get status error on an approved run",
+			status:         model.PayrollRunApproved,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrPayrollRunStatus,
+		},
+		{
+			name:           "should get status error when the run moved on concurrently",
+			status:         model.PayrollRunDraft,
+			updateErr:      model.ErrPayrollRunStatus,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrPayrollRunStatus,
+		},
+		{
+			name:           "should get not found error",
+			findErr:        gorm.ErrRecordNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				runMockRepo      mocks.PayrollRunRepository
+				auditLogMockRepo mocks.AuditLogRepository
+				transactorMock   mocks.Transactor
+			)
+			useCase := NewPayrollRunUsecase(&runMockRepo, nil, nil, nil, nil, nil, nil, &auditLogMockRepo, &transactorMock,
+				flatTax{}, contributionRules)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			var existing *model.PayrollRun
+			if test.findErr == nil {
+				existing = &model.PayrollRun{ID: 1, Status: test.status}
+			}
+			runMockRepo.On("FindByID", ctx, 1).Return(existing, test.findErr).Once()
+			runMockRepo.On("UpdateStatus", ctx, mock.MatchedBy(func(run *model.PayrollRun) bool {
+				return run.Status == model.PayrollRunApproved && run.ApprovedBy == model.AuditActorSystem &&
+					run.ApprovedAt != nil
+			}), model.PayrollRunDraft).Return(test.updateErr).Once()
+			auditLogMockRepo.On("Create", ctx, mock.AnythingOfType("*model.AuditLog")).Return(&model.AuditLog{}, nil).Once()
+
+			run, status, err := useCase.Approve(ctx, 1)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedStatus, status)
+			if test.expectedErr == nil {
+				assert.Equal(t, model.PayrollRunApproved, run.Status)
+				assert.Equal(t, model.PayrollRunDraft, existing.Status)
+			}
+		})
+	}
+}
+
+func TestCancelPayrollRun(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		status      string
+		expectedErr error
+	}{
+		{
+			name:   "should cancel a draft run",
+			status: model.PayrollRunDraft,
+		},
+		{
+			name:   "should cancel an approved run",
+			status: model.PayrollRunApproved,
+		},
+		{
+			name:        "should get status error on an executed run",
+			status:      model.PayrollRunExecuted,
+			expectedErr: model.ErrPayrollRunStatus,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				runMockRepo      mocks.PayrollRunRepository
+				auditLogMockRepo mocks.AuditLogRepository
+				transactorMock   mocks.Transactor
+			)
+			useCase := NewPayrollRunUsecase(&runMockRepo, nil, nil, nil, nil, nil, nil, &auditLogMockRepo, &transactorMock,
+				flatTax{}, contributionRules)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			runMockRepo.On("FindByID", ctx, 1).Return(&model.PayrollRun{ID: 1, Status: test.status}, nil).Once()
+			runMockRepo.On("UpdateStatus", ctx, mock.MatchedBy(func(run *model.PayrollRun) bool {
+				return run.Status == model.PayrollRunCancelled && run.CancelledAt != nil
+			}), test.status).Return(nil).Once()
+			auditLogMockRepo.On("Create", ctx, mock.AnythingOfType("*model.AuditLog")).Return(&model.AuditLog{}, nil).Once()
+
+			run, _, err := useCase.Cancel(ctx, 1)
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr == nil {
+				assert.Equal(t, model.PayrollRunCancelled, run.Status)
+			}
+		})
+	}
+}
+
+func TestExecutePayrollRun(t *testing.T) {
+	ctx := context.Background()
+	userData := &model.User{
+		ID:         1,
+		Name:       "user",
+		PositionID: 1,
+		Position:   &model.Position{ID: 1, Name: "Manager", Salary: 120000},
+	}
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	pay := withheld(model.NewPayBreakdown(120000, nil))
+	approved := func() *model.PayrollRun {
+		run := &model.PayrollRun{
+			ID:          1,
+			Period:      period.Key,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+			Status:      model.PayrollRunApproved,
+		}
+		run.Add(userData, pay)
+		return run
+	}
+	tests := []struct {
+		name           string
+		status         string
+		balance        int
+		userErr        error
+		withdrawal     *model.Withdrawal
+		debitErr       error
+		taxDebitErr    error
+		updateErr      error
+		expectedStatus int
+		expectedErr    error
+	}{
+		{
+			name:           "should pay every employee of the run",
+			balance:        pay.Cost(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "should fail the whole run on insufficient balance",
+			balance:        pay.Cost() - 1,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrInsufficientBalance,
+		},
+		{
+			name:           "should get status error on a draft run",
+			status:         model.PayrollRunDraft,
+			balance:        pay.Cost(),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrPayrollRunStatus,
+		},
+		{
+			name:           "should get already withdrawn error for an employee paid since the draft",
+			balance:        pay.Cost(),
+			withdrawal:     &model.Withdrawal{UserID: userData.ID, Period: period.Key},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrAlreadyWithdrawn,
+		},
+		{
+			name:           "should get not found error for a deleted employee",
+			balance:        pay.Cost(),
+			userErr:        gorm.ErrRecordNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    gorm.ErrRecordNotFound,
+		},
+		{
+			name:           "should get some error while debit salary",
+			balance:        pay.Cost(),
+			debitErr:       errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get some error while debit tax",
+			balance:        pay.Cost(),
+			taxDebitErr:    errors.New("some error"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get status error when the run was executed concurrently",
+			balance:        pay.Cost(),
+			updateErr:      model.ErrPayrollRunStatus,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedErr:    model.ErrPayrollRunStatus,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				runMockRepo        mocks.PayrollRunRepository
+				userMockRepo       mocks.UserRepository
+				companyMockRepo    mocks.CompanyRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+				payslipMockRepo    mocks.PayslipRepository
+				auditLogMockRepo   mocks.AuditLogRepository
+				transactorMock     mocks.Transactor
+			)
+			useCase := NewPayrollRunUsecase(&runMockRepo, &userMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo,
+				&payslipMockRepo, &auditLogMockRepo, &transactorMock, flatTax{}, contributionRules)
+
+			companyData := &model.Company{ID: 1, Name: "Test Company", Balance: test.balance}
+			run := approved()
+			if test.status != "" {
+				run.Status = test.status
+			}
+			findWithdrawalErr := gorm.ErrRecordNotFound
+			if test.withdrawal != nil {
+				findWithdrawalErr = nil
+			}
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			runMockRepo.On("FindByID", ctx, 1).Return(run, nil).Once()
+			companyMockRepo.On("GetForUpdate", ctx).Return(companyData, nil).Once()
+			userMockRepo.On("FindByID", ctx, userData.ID).Return(userData, test.userErr).Once()
+			withdrawalMockRepo.On("FindOverlapping", ctx, userData.ID, period).
+				Return(test.withdrawal, findWithdrawalErr).Once()
+			companyMockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:     pay.NetPay,
+				Note:       "user payroll run 1",
+				Category:   model.TransactionCategorySalaryWithdrawal,
+				Reference:  "SAL-" + period.Key + "-1",
+				UserID:     &userData.ID,
+				PositionID: &userData.PositionID,
+			}).Return(test.debitErr).Once()
+			withdrawalMockRepo.On("Create", ctx, &model.Withdrawal{
+				UserID:          userData.ID,
+				Period:          period.Key,
+				PeriodStart:     period.Start,
+				PeriodEnd:       period.End,
+				Amount:          pay.NetPay,
+				GrossPay:        pay.GrossPay,
+				Tax:             pay.Tax,
+				TaxableBenefits: pay.EmployerContributions,
+			}).Return(&model.Withdrawal{}, nil).Once()
+			payslipMockRepo.On("Create", ctx, mock.AnythingOfType("*model.Payslip")).Return(&model.Payslip{}, nil).Once()
+			companyMockRepo.On("DebitBalance", ctx, mock.MatchedBy(func(transaction *model.Transaction) bool {
+				return transaction.Category == model.TransactionCategoryTax
+			})).Return(test.taxDebitErr).Once()
+			companyMockRepo.On("DebitBalance", ctx, mock.MatchedBy(func(transaction *model.Transaction) bool {
+				return transaction.Category == model.TransactionCategoryEmployeeContribution ||
+					transaction.Category == model.TransactionCategoryEmployerContribution
+			})).Return(nil).Twice()
+			runMockRepo.On("UpdateStatus", ctx, mock.MatchedBy(func(run *model.PayrollRun) bool {
+				return run.Status == model.PayrollRunExecuted && run.ExecutedAt != nil
+			}), model.PayrollRunApproved).Return(test.updateErr).Once()
+			auditLogMockRepo.On("Create", ctx, mock.AnythingOfType("*model.AuditLog")).Return(&model.AuditLog{}, nil).Once()
+
+			res, status, err := useCase.Execute(ctx, 1)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedStatus, status)
+			if test.expectedErr == nil {
+				assert.Equal(t, model.PayrollRunExecuted, res.Status)
+				companyMockRepo.AssertNumberOfCalls(t, "DebitBalance", 4)
+			}
+			if test.expectedErr == model.ErrInsufficientBalance {
+				companyMockRepo.AssertNotCalled(t, "DebitBalance", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// recordingTax withholds like flatTax and keeps the salaries it was asked to.
+type recordingTax struct {
+	flatTax
+	salaries *[]model.TaxableSalary
+}
+
+func (r recordingTax) Withhold(salary model.TaxableSalary) int {
+	*r.salaries = append(*r.salaries, salary)
+	return r.flatTax.Withhold(salary)
+}
+
+func TestPayrollForecast(t *testing.T) {
+	ctx := context.Background()
+	companyData := &model.Company{ID: 1, Balance: 250000, PayPeriod: model.PayPeriodMonthly}
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	next := model.NewPayPeriod(model.PayPeriodMonthly, period.End)
+	last := model.NewPayPeriod(model.PayPeriodMonthly, next.End)
+	users := []*model.User{
+		{ID: 1, Name: "first", PositionID: 1},
+		{ID: 2, Name: "second", PositionID: 1},
+	}
+	// a raise to 150000 is scheduled for the start of the next period
+	cost := withheld(model.NewPayBreakdown(120000, nil)).Cost()
+	raisedCost := withheld(model.NewPayBreakdown(150000, nil)).Cost()
+	tests := []struct {
+		name              string
+		req               request.ForecastRequest
+		companyErr        error
+		usersErr          error
+		findErr           error
+		secondPaid        bool
+		expectedCosts     []int
+		expectedShortfall int
+		expectedStatus    int
+		expectedErr       error
+	}{
+		{
+			name:              "should forecast three periods by default",
+			expectedCosts:     []int{2 * cost, 2 * raisedCost, 2 * raisedCost},
+			expectedShortfall: 2*cost + 4*raisedCost - companyData.Balance,
+			expectedStatus:    http.StatusOK,
+		},
+		{
+			name:              "should leave out employees paid for the current period",
+			req:               request.ForecastRequest{Periods: 2},
+			secondPaid:        true,
+			expectedCosts:     []int{cost, 2 * raisedCost},
+			expectedShortfall: cost + 2*raisedCost - companyData.Balance,
+			expectedStatus:    http.StatusOK,
+		},
+		{
+			name:           "should get some error while get company",
+			companyErr:     gorm.ErrRecordNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    gorm.ErrRecordNotFound,
+		},
+		{
+			name:           "should get some error while fetch employees",
+			usersErr:       errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should get some error while find withdrawal",
+			findErr:        errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo       mocks.UserRepository
+				salaryMockRepo     mocks.SalaryVersionRepository
+				componentMockRepo  mocks.PayComponentRepository
+				companyMockRepo    mocks.CompanyRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+				salaries           []model.TaxableSalary
+			)
+			useCase := NewPayrollRunUsecase(nil, &userMockRepo, &salaryMockRepo, &componentMockRepo, &companyMockRepo,
+				&withdrawalMockRepo, nil, nil, nil, recordingTax{salaries: &salaries}, contributionRules)
+
+			companyMockRepo.On("Get", ctx).Return(companyData, test.companyErr).Once()
+			userMockRepo.On("Fetch", ctx, 0, 0).Return(users, test.usersErr).Once()
+			salaryMockRepo.On("FindEffective", ctx, 1, mock.MatchedBy(func(until time.Time) bool {
+				return !until.After(next.Start)
+			})).Return(&model.SalaryVersion{Salary: 120000}, nil)
+			salaryMockRepo.On("FindEffective", ctx, 1, mock.MatchedBy(func(until time.Time) bool {
+				return until.After(next.Start)
+			})).Return(&model.SalaryVersion{Salary: 150000}, nil)
+			componentMockRepo.On("FetchByUser", ctx, mock.Anything).Return(nil, nil)
+			withdrawalMockRepo.On("FetchByUserBetween", ctx, mock.Anything, mock.AnythingOfType("time.Time"),
+				mock.AnythingOfType("time.Time")).Return([]*model.Withdrawal{}, nil)
+			withdrawalMockRepo.On("FindOverlapping", ctx, 1, period).
+				Return(nil, gorm.ErrRecordNotFound).Once()
+			if test.secondPaid {
+				withdrawalMockRepo.On("FindOverlapping", ctx, 2, period).Return(&model.Withdrawal{}, nil).Once()
+			} else if test.findErr != nil {
+				withdrawalMockRepo.On("FindOverlapping", ctx, 2, period).Return(nil, test.findErr).Once()
+			} else {
+				withdrawalMockRepo.On("FindOverlapping", ctx, 2, period).
+					Return(nil, gorm.ErrRecordNotFound).Once()
+			}
+
+			forecast, status, err := useCase.Forecast(ctx, test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedStatus, status)
+			if test.expectedErr != nil {
+				assert.Nil(t, forecast)
+				return
+			}
+
+			var costs []int
+			for _, forecastPeriod := range forecast.Periods {
+				costs = append(costs, forecastPeriod.Cost)
+			}
+			assert.Equal(t, test.expectedCosts, costs)
+			assert.Equal(t, period.Key, forecast.Periods[0].Key)
+			assert.Equal(t, period.End, forecast.Periods[1].Start)
+			assert.Equal(t, test.expectedShortfall, forecast.Shortfall)
+			assert.NotNil(t, forecast.RunsOutAt)
+			withdrawalMockRepo.AssertNotCalled(t, "FindOverlapping", ctx, mock.Anything, next)
+
+			if len(test.expectedCosts) < 3 {
+				return
+			}
+
+			// the last period is taxed as if the periods before it in the same
+			// tax year were paid
+			yearGrossPay := 0
+			for _, earlier := range []struct {
+				period   model.PayPeriod
+				grossPay int
+			}{{period, 120000}, {next, 150000}} {
+				if !earlier.period.Start.Before(last.TaxYearStart()) {
+					yearGrossPay += earlier.grossPay
+				}
+			}
+			// the salaries of the first employee come first in every period
+			assert.Equal(t, last, salaries[4].Period)
+			assert.Equal(t, yearGrossPay, salaries[4].YearGrossPay)
+		})
+	}
+}
@@ -2,16 +2,29 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"self-payrol/model"
 	"self-payrol/request"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type positionUsecase struct {
 	positionRepository model.PositionRepository
+	salaryRepo         model.SalaryVersionRepository
+	auditLogRepo       model.AuditLogRepository
+	transactor         model.Transactor
 }
 
-func NewPositionUsecase(position model.PositionRepository) model.PositionUsecase {
-	return &positionUsecase{positionRepository: position}
+func NewPositionUsecase(position model.PositionRepository, salary model.SalaryVersionRepository,
+	auditLog model.AuditLogRepository, transactor model.Transactor) model.PositionUsecase {
+	return &positionUsecase{
+		positionRepository: position,
+		salaryRepo:         salary,
+		auditLogRepo:       auditLog,
+		transactor:         transactor,
+	}
 }
 
 func (p *positionUsecase) GetByID(ctx context.Context, id int) (*model.Position, error) {
@@ -35,25 +48,81 @@ func (p *positionUsecase) FetchPosition(ctx context.Context, limit, offset int)
 }
 
 func (p *positionUsecase) DestroyPosition(ctx context.Context, id int) error {
-	err := p.positionRepository.Delete(ctx, id)
+	existing, err := p.positionRepository.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.positionRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionDelete, model.AuditEntityPosition, id, existing, nil)
+	})
 }
 
+// EditPosition updates the position with id. A new salary, or one with an
+// effective date, is added to the salary history and the position keeps the
+// salary that is in effect now, so a backdated change does not override a
+// later one. A salary taking effect after today is scheduled and applied by
+// ApplySalaryChanges once its date arrives. A position without a salary
+// history is given one, starting with its current salary, first.
 func (p *positionUsecase) EditPosition(ctx context.Context, id int, req *request.PositionRequest) (*model.Position, error) {
-	_, err := p.positionRepository.FindByID(ctx, id)
+	now := time.Now()
+
+	effectiveFrom, err := salaryEffectiveFrom(req.EffectiveFrom, now)
 	if err != nil {
 		return nil, err
 	}
 
-	position, err := p.positionRepository.UpdateByID(ctx, id, &model.Position{
-		Name:   req.Name,
-		Salary: req.Salary,
-	})
+	var position *model.Position
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := p.positionRepository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		salary := existing.Salary
+
+		if req.Salary != existing.Salary || req.EffectiveFrom != "" {
+			if err := p.seedSalaryHistory(ctx, existing); err != nil {
+				return err
+			}
+
+			version := &model.SalaryVersion{
+				PositionID:    id,
+				Salary:        req.Salary,
+				EffectiveFrom: effectiveFrom,
+			}
+			if !effectiveFrom.After(now) {
+				version.AppliedAt = &now
+			}
+
+			_, err = p.salaryRepo.Create(ctx, version)
+			if err != nil {
+				return err
+			}
 
+			current, err := p.salaryRepo.FindEffective(ctx, id, now)
+			if err != nil {
+				return err
+			}
+
+			salary = current.Salary
+		}
+
+		position, err = p.positionRepository.UpdateByID(ctx, id, &model.Position{
+			Name:   req.Name,
+			Salary: salary,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityPosition, id, existing, position)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -62,16 +131,218 @@ func (p *positionUsecase) EditPosition(ctx context.Context, id int, req *request
 }
 
 func (p *positionUsecase) StorePosition(ctx context.Context, req *request.PositionRequest) (*model.Position, error) {
+	now := time.Now()
+
+	effectiveFrom, err := salaryEffectiveFrom(req.EffectiveFrom, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if effectiveFrom.After(now) {
+		return nil, model.ErrFutureEffectiveDate
+	}
+
 	newPosition := &model.Position{
 		Name:   req.Name,
 		Salary: req.Salary,
 	}
 
-	position, err := p.positionRepository.Create(ctx, newPosition)
+	var position *model.Position
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		position, err = p.positionRepository.Create(ctx, newPosition)
+		if err != nil {
+			return err
+		}
+
+		_, err = p.salaryRepo.Create(ctx, &model.SalaryVersion{
+			PositionID:    position.ID,
+			Salary:        position.Salary,
+			EffectiveFrom: effectiveFrom,
+			AppliedAt:     &now,
+		})
+		if err != nil {
+			return err
+		}
 
+		return audit(ctx, p.auditLogRepo, model.AuditActionCreate, model.AuditEntityPosition, position.ID, nil, position)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return position, nil
 }
+
+// SalaryHistory lists the salaries of the position with id, the latest
+// effective date first.
+func (p *positionUsecase) SalaryHistory(ctx context.Context, id int) ([]*model.SalaryVersion, error) {
+	_, err := p.positionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.salaryRepo.FetchByPosition(ctx, id)
+}
+
+// ScheduleSalaryChange adds a salary for the position with id that takes
+// effect on a date after today.
+func (p *positionUsecase) ScheduleSalaryChange(ctx context.Context, id int, req *request.SalaryChangeRequest) (*model.SalaryVersion, error) {
+	effectiveFrom, err := salaryEffectiveFrom(req.EffectiveFrom, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if !effectiveFrom.After(time.Now()) {
+		return nil, model.ErrPastEffectiveDate
+	}
+
+	var version *model.SalaryVersion
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		position, err := p.positionRepository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := p.seedSalaryHistory(ctx, position); err != nil {
+			return err
+		}
+
+		version, err = p.salaryRepo.Create(ctx, &model.SalaryVersion{
+			PositionID:    id,
+			Salary:        req.Salary,
+			EffectiveFrom: effectiveFrom,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionCreate, model.AuditEntitySalaryChange, version.ID, nil, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// PendingSalaryChanges lists the salaries of the position with id that are
+// not applied yet, the earliest effective date first.
+func (p *positionUsecase) PendingSalaryChanges(ctx context.Context, id int) ([]*model.SalaryVersion, error) {
+	_, err := p.positionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.salaryRepo.FetchPending(ctx, id)
+}
+
+// CancelSalaryChange cancels the scheduled salary changeID of the position
+// with id. Changes that already took effect cannot be cancelled.
+func (p *positionUsecase) CancelSalaryChange(ctx context.Context, id, changeID int) error {
+	_, err := p.positionRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.salaryRepo.Cancel(ctx, id, changeID, now); err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionDelete, model.AuditEntitySalaryChange, changeID,
+			map[string]interface{}{"position_id": id}, nil)
+	})
+}
+
+// ApplySalaryChanges moves every position with a scheduled salary that took
+// effect by now to that salary, and returns how many changes were applied.
+func (p *positionUsecase) ApplySalaryChanges(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	due, err := p.salaryRepo.FetchDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, version := range due {
+		err := p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return p.applySalaryChange(ctx, version, now)
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(due), nil
+}
+
+func (p *positionUsecase) applySalaryChange(ctx context.Context, version *model.SalaryVersion, now time.Time) error {
+	existing, err := p.positionRepository.FindByID(ctx, version.PositionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// the position was removed before the change took effect
+		return p.salaryRepo.MarkApplied(ctx, version.ID, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	// a later change may have taken effect by now as well
+	current, err := p.salaryRepo.FindEffective(ctx, version.PositionID, now)
+	if err != nil {
+		return err
+	}
+
+	if current.Salary != existing.Salary {
+		position, err := p.positionRepository.UpdateByID(ctx, existing.ID, &model.Position{Salary: current.Salary})
+		if err != nil {
+			return err
+		}
+
+		err = audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityPosition, existing.ID, existing, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.salaryRepo.MarkApplied(ctx, version.ID, now)
+}
+
+// seedSalaryHistory records the salary of position as its first version, in
+// effect since the position was created, when it has no salary history yet.
+// Positions created before salaries were versioned would otherwise have a new
+// salary paid for the periods before it took effect as well.
+func (p *positionUsecase) seedSalaryHistory(ctx context.Context, position *model.Position) error {
+	history, err := p.salaryRepo.FetchByPosition(ctx, position.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(history) > 0 {
+		return nil
+	}
+
+	createdAt := position.CreatedAt
+
+	_, err = p.salaryRepo.Create(ctx, &model.SalaryVersion{
+		PositionID:    position.ID,
+		Salary:        position.Salary,
+		EffectiveFrom: createdAt,
+		AppliedAt:     &createdAt,
+	})
+
+	return err
+}
+
+// salaryEffectiveFrom parses the effective date of a salary change, which
+// defaults to the day of now.
+func salaryEffectiveFrom(date string, now time.Time) (time.Time, error) {
+	if date == "" {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+
+	return time.ParseInLocation("2006-01-02", date, now.Location())
+}
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestGetPositionByID(t *testing.T) {
 	var mockRepo mocks.PositionRepository
-	useCase := NewPositionUsecase(&mockRepo)
+	useCase := NewPositionUsecase(&mockRepo, nil, nil, nil)
 	ctx := context.Background()
 	positionData := &model.Position{
 		ID:        1,
@@ -58,7 +60,7 @@ func TestGetPositionByID(t *testing.T) {
 
 func TestFetchPosition(t *testing.T) {
 	var mockRepo mocks.PositionRepository
-	useCase := NewPositionUsecase(&mockRepo)
+	useCase := NewPositionUsecase(&mockRepo, nil, nil, nil)
 	ctx := context.Background()
 	positionData := []*model.Position{
 		{
@@ -110,12 +112,18 @@ func TestFetchPosition(t *testing.T) {
 }
 
 func TestDestroyPosition(t *testing.T) {
-	var mockRepo mocks.PositionRepository
-	useCase := NewPositionUsecase(&mockRepo)
+	var (
+		mockRepo       mocks.PositionRepository
+		auditMockRepo  mocks.AuditLogRepository
+		transactorMock mocks.Transactor
+	)
+	useCase := NewPositionUsecase(&mockRepo, nil, &auditMockRepo, &transactorMock)
 	ctx := context.Background()
+	positionData := &model.Position{ID: 1, Name: "Manager", Salary: 200000}
 	tests := []struct {
 		name        string
 		id          int
+		findErr     error
 		err         error
 		expectedErr error
 	}{
@@ -129,11 +137,24 @@ func TestDestroyPosition(t *testing.T) {
 			err:         errors.New("some error"),
 			expectedErr: errors.New("some error"),
 		},
+		{
+			name:        "should return not found error while position is not exist",
+			id:          1,
+			findErr:     errors.New("Not Found"),
+			expectedErr: errors.New("Not Found"),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("FindByID", ctx, test.id).Return(positionData, test.findErr).Once()
 			mockRepo.On("Delete", ctx, test.id).Return(test.err).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Action == model.AuditActionDelete && log.EntityID == test.id &&
+					log.Before["name"] == "Manager" && len(log.After) == 0
+			})).Return(nil, nil).Once()
 			err := useCase.DestroyPosition(ctx, test.id)
 
 			assert.Equal(t, test.expectedErr, err)
@@ -142,9 +163,11 @@ func TestDestroyPosition(t *testing.T) {
 }
 
 func TestEditPosition(t *testing.T) {
-	var mockRepo mocks.PositionRepository
-	useCase := NewPositionUsecase(&mockRepo)
 	ctx := context.Background()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	createdAt := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.Local)
+	existing := &model.Position{ID: 1, Name: "Staff", Salary: 100000, CreatedAt: createdAt}
 	positionData := &model.Position{
 		ID:        1,
 		Name:      "Manager",
@@ -153,30 +176,79 @@ func TestEditPosition(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 	tests := []struct {
-		name         string
-		data         *model.Position
-		id           int
-		findErr      error
-		updateErr    error
-		expectedResp *model.Position
-		expectedErr  error
+		name            string
+		req             *request.PositionRequest
+		findErr         error
+		unversioned     bool
+		createErr       error
+		current         *model.SalaryVersion
+		updateErr       error
+		expectedVersion *model.SalaryVersion
+		expectedSalary  int
+		expectedResp    *model.Position
+		expectedErr     error
 	}{
 		{
-			name:         "should edit position successfully",
-			data:         positionData,
-			id:           1,
-			expectedResp: positionData,
+			name:            "should edit position successfully",
+			req:             &request.PositionRequest{Name: "Manager", Salary: 200000},
+			current:         &model.SalaryVersion{Salary: 200000},
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 200000, EffectiveFrom: today},
+			expectedSalary:  200000,
+			expectedResp:    positionData,
 		},
 		{
-			name:        "should return error while update",
-			id:          1,
-			data:        positionData,
-			updateErr:   errors.New("some error"),
-			expectedErr: errors.New("some error"),
+			name:           "should keep the salary history when the salary is unchanged",
+			req:            &request.PositionRequest{Name: "Manager", Salary: 100000},
+			expectedSalary: 100000,
+			expectedResp:   positionData,
+		},
+		{
+			name:    "should keep the later salary on a backdated change",
+			req:     &request.PositionRequest{Name: "Manager", Salary: 150000, EffectiveFrom: "2022-01-01"},
+			current: &model.SalaryVersion{Salary: 100000},
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 150000,
+				EffectiveFrom: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.Local)},
+			expectedSalary: 100000,
+			expectedResp:   positionData,
+		},
+		{
+			name:    "should schedule a salary taking effect in the future",
+			req:     &request.PositionRequest{Name: "Manager", Salary: 200000, EffectiveFrom: today.AddDate(0, 0, 2).Format("2006-01-02")},
+			current: &model.SalaryVersion{Salary: 100000},
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 200000,
+				EffectiveFrom: today.AddDate(0, 0, 2)},
+			expectedSalary: 100000,
+			expectedResp:   positionData,
+		},
+		{
+			name:        "should seed the salary history of a position without one",
+			req:         &request.PositionRequest{Name: "Manager", Salary: 200000, EffectiveFrom: today.AddDate(0, 0, 2).Format("2006-01-02")},
+			unversioned: true,
+			current:     &model.SalaryVersion{Salary: 100000},
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 200000,
+				EffectiveFrom: today.AddDate(0, 0, 2)},
+			expectedSalary: 100000,
+			expectedResp:   positionData,
+		},
+		{
+			name:            "should return error while create salary version",
+			req:             &request.PositionRequest{Name: "Manager", Salary: 200000},
+			createErr:       errors.New("some error"),
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 200000, EffectiveFrom: today},
+			expectedErr:     errors.New("some error"),
+		},
+		{
+			name:            "should return error while update",
+			req:             &request.PositionRequest{Name: "Manager", Salary: 200000},
+			current:         &model.SalaryVersion{Salary: 200000},
+			expectedVersion: &model.SalaryVersion{PositionID: 1, Salary: 200000, EffectiveFrom: today},
+			expectedSalary:  200000,
+			updateErr:       errors.New("some error"),
+			expectedErr:     errors.New("some error"),
 		},
 		{
 			name:        "should return not found error while position is not exist",
-			id:          1,
+			req:         &request.PositionRequest{Name: "Manager", Salary: 200000},
 			findErr:     errors.New("Not Found"),
 			expectedErr: errors.New("Not Found"),
 		},
@@ -184,27 +256,66 @@ func TestEditPosition(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			mockRepo.On("FindByID", ctx, test.id).Return(test.data, test.findErr).Once()
-			mockRepo.On("UpdateByID", ctx, test.id, &model.Position{
-				Name:   positionData.Name,
-				Salary: positionData.Salary,
-			}).Return(test.data, test.updateErr).Once()
-			result, err := useCase.EditPosition(ctx, test.id, &request.PositionRequest{
-				Name:   positionData.Name,
-				Salary: positionData.Salary,
-			})
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, &auditMockRepo, &transactorMock)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("FindByID", ctx, 1).Return(existing, test.findErr).Once()
+			history := []*model.SalaryVersion{{ID: 1, PositionID: 1, Salary: 100000}}
+			seed := &model.SalaryVersion{PositionID: 1, Salary: 100000, EffectiveFrom: createdAt, AppliedAt: &createdAt}
+			if test.unversioned {
+				history = nil
+				salaryMockRepo.On("Create", ctx, seed).Return(seed, nil).Once()
+			}
+			salaryMockRepo.On("FetchByPosition", ctx, 1).Return(history, nil).Once()
+			var created *model.SalaryVersion
+			salaryMockRepo.On("Create", ctx, mock.Anything).Return(test.expectedVersion, test.createErr).
+				Run(func(args mock.Arguments) { created = args.Get(1).(*model.SalaryVersion) }).Once()
+			salaryMockRepo.On("FindEffective", ctx, 1, mock.AnythingOfType("time.Time")).Return(test.current, nil).Once()
+			mockRepo.On("UpdateByID", ctx, 1, &model.Position{
+				Name:   test.req.Name,
+				Salary: test.expectedSalary,
+			}).Return(positionData, test.updateErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return assert.ObjectsAreEqual(map[string]interface{}{"name": "Staff", "salary": float64(100000)}, log.Before) &&
+					assert.ObjectsAreEqual(map[string]interface{}{"name": "Manager", "salary": float64(200000)}, log.After)
+			})).Return(nil, nil).Once()
+
+			result, err := useCase.EditPosition(ctx, 1, test.req)
 
 			assert.Equal(t, test.expectedResp, result)
 			assert.Equal(t, test.expectedErr, err)
+			if test.expectedVersion == nil {
+				salaryMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+
+			// the salary until the change stays the one the position had
+			if test.unversioned {
+				salaryMockRepo.AssertCalled(t, "Create", ctx, seed)
+			} else {
+				salaryMockRepo.AssertNotCalled(t, "Create", ctx, seed)
+			}
+
+			// only salaries in effect today are applied right away
+			assert.Equal(t, !test.expectedVersion.EffectiveFrom.After(now), created.AppliedAt != nil)
+			created.AppliedAt = nil
+			assert.Equal(t, test.expectedVersion, created)
 		})
 	}
 }
 
 func TestStorePosition(t *testing.T) {
-	var mockRepo mocks.PositionRepository
-	useCase := NewPositionUsecase(&mockRepo)
 	ctx := context.Background()
+	now := time.Now()
 	positionData := &model.Position{
+		ID:        1,
 		Name:      "Manager",
 		Salary:    200000,
 		CreatedAt: time.Now(),
@@ -212,37 +323,307 @@ func TestStorePosition(t *testing.T) {
 	}
 	tests := []struct {
 		name         string
-		data         *model.Position
 		err          error
+		versionErr   error
 		expectedResp *model.Position
 		expectedErr  error
 	}{
 		{
 			name:         "should store position successfully",
-			data:         positionData,
 			expectedResp: positionData,
 		},
 		{
 			name:        "should return error",
-			data:        positionData,
 			err:         errors.New("some error"),
 			expectedErr: errors.New("some error"),
 		},
+		{
+			name:        "should return error while create salary version",
+			versionErr:  errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, &auditMockRepo, &transactorMock)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
 			mockRepo.On("Create", ctx, &model.Position{
 				Name:   positionData.Name,
 				Salary: positionData.Salary,
-			}).Return(test.data, test.err).Once()
+			}).Return(positionData, test.err).Once()
+			salaryMockRepo.On("Create", ctx, mock.MatchedBy(func(version *model.SalaryVersion) bool {
+				return version.AppliedAt != nil && *version == model.SalaryVersion{
+					PositionID:    1,
+					Salary:        positionData.Salary,
+					EffectiveFrom: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local),
+					AppliedAt:     version.AppliedAt,
+				}
+			})).Return(nil, test.versionErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Action == model.AuditActionCreate && len(log.Before) == 0 && log.After["name"] == "Manager"
+			})).Return(nil, nil).Once()
+
 			result, err := useCase.StorePosition(ctx, &request.PositionRequest{
 				Name:   positionData.Name,
 				Salary: positionData.Salary,
 			})
 
+			if test.expectedErr == nil {
+				assert.Equal(t, test.expectedResp, result)
+			} else {
+				assert.Nil(t, result)
+			}
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestSalaryHistory(t *testing.T) {
+	ctx := context.Background()
+	history := []*model.SalaryVersion{
+		{ID: 2, PositionID: 1, Salary: 200000, EffectiveFrom: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.Local)},
+		{ID: 1, PositionID: 1, Salary: 100000, EffectiveFrom: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.Local)},
+	}
+	tests := []struct {
+		name         string
+		findErr      error
+		expectedResp []*model.SalaryVersion
+		expectedErr  error
+	}{
+		{
+			name:         "should list the salary history",
+			expectedResp: history,
+		},
+		{
+			name:        "should return not found error while position is not exist",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, nil, nil)
+
+			mockRepo.On("FindByID", ctx, 1).Return(&model.Position{ID: 1}, test.findErr).Once()
+			salaryMockRepo.On("FetchByPosition", ctx, 1).Return(history, nil).Once()
+
+			result, err := useCase.SalaryHistory(ctx, 1)
+
 			assert.Equal(t, test.expectedResp, result)
 			assert.Equal(t, test.expectedErr, err)
 		})
 	}
 }
+
+func TestScheduleSalaryChange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name        string
+		req         *request.SalaryChangeRequest
+		findErr     error
+		createErr   error
+		expectedErr error
+	}{
+		{
+			name: "should schedule a salary change successfully",
+			req:  &request.SalaryChangeRequest{Salary: 250000, EffectiveFrom: nextMonth.Format("2006-01-02")},
+		},
+		{
+			name:        "should refuse a salary change taking effect today",
+			req:         &request.SalaryChangeRequest{Salary: 250000, EffectiveFrom: now.Format("2006-01-02")},
+			expectedErr: model.ErrPastEffectiveDate,
+		},
+		{
+			name:        "should return not found error while position is not exist",
+			req:         &request.SalaryChangeRequest{Salary: 250000, EffectiveFrom: nextMonth.Format("2006-01-02")},
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should return error while create salary version",
+			req:         &request.SalaryChangeRequest{Salary: 250000, EffectiveFrom: nextMonth.Format("2006-01-02")},
+			createErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, &auditMockRepo, &transactorMock)
+			version := &model.SalaryVersion{PositionID: 1, Salary: 250000, EffectiveFrom: nextMonth}
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("FindByID", ctx, 1).Return(&model.Position{ID: 1}, test.findErr).Once()
+			salaryMockRepo.On("FetchByPosition", ctx, 1).Return([]*model.SalaryVersion{{ID: 1, PositionID: 1}}, nil).Once()
+			salaryMockRepo.On("Create", ctx, version).Return(version, test.createErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.EntityType == model.AuditEntitySalaryChange && log.After["salary"] == float64(250000)
+			})).Return(nil, nil).Once()
+
+			result, err := useCase.ScheduleSalaryChange(ctx, 1, test.req)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr == nil {
+				assert.Equal(t, version, result)
+				assert.Nil(t, result.AppliedAt)
+			}
+		})
+	}
+}
+
+func TestCancelSalaryChange(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		findErr     error
+		cancelErr   error
+		expectedErr error
+	}{
+		{
+			name: "should cancel a salary change successfully",
+		},
+		{
+			name:        "should refuse a salary change that took effect",
+			cancelErr:   model.ErrSalaryChangeNotPending,
+			expectedErr: model.ErrSalaryChangeNotPending,
+		},
+		{
+			name:        "should return not found error while position is not exist",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, &auditMockRepo, &transactorMock)
+
+			mockRepo.On("FindByID", ctx, 1).Return(&model.Position{ID: 1}, test.findErr).Once()
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			salaryMockRepo.On("Cancel", ctx, 1, 5, mock.AnythingOfType("time.Time")).Return(test.cancelErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Action == model.AuditActionDelete && log.EntityID == 5
+			})).Return(nil, nil).Once()
+
+			err := useCase.CancelSalaryChange(ctx, 1, 5)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				auditMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestApplySalaryChanges(t *testing.T) {
+	ctx := context.Background()
+	due := []*model.SalaryVersion{{ID: 5, PositionID: 1, Salary: 250000}}
+	tests := []struct {
+		name          string
+		fetchErr      error
+		position      *model.Position
+		findErr       error
+		current       *model.SalaryVersion
+		updateErr     error
+		expectedCount int
+		expectedErr   error
+		expectUpdate  bool
+	}{
+		{
+			name:          "should move the position to the scheduled salary",
+			position:      &model.Position{ID: 1, Name: "Manager", Salary: 200000},
+			current:       &model.SalaryVersion{ID: 5, Salary: 250000},
+			expectedCount: 1,
+			expectUpdate:  true,
+		},
+		{
+			name:          "should keep the salary when a later change is in effect too",
+			position:      &model.Position{ID: 1, Name: "Manager", Salary: 300000},
+			current:       &model.SalaryVersion{ID: 6, Salary: 300000},
+			expectedCount: 1,
+		},
+		{
+			name:          "should mark the change applied when the position is gone",
+			findErr:       gorm.ErrRecordNotFound,
+			expectedCount: 1,
+		},
+		{
+			name:        "should get some error while fetch due changes",
+			fetchErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name:         "should get some error while update position",
+			position:     &model.Position{ID: 1, Name: "Manager", Salary: 200000},
+			current:      &model.SalaryVersion{ID: 5, Salary: 250000},
+			updateErr:    errors.New("some error"),
+			expectedErr:  errors.New("some error"),
+			expectUpdate: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mockRepo       mocks.PositionRepository
+				salaryMockRepo mocks.SalaryVersionRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewPositionUsecase(&mockRepo, &salaryMockRepo, &auditMockRepo, &transactorMock)
+
+			salaryMockRepo.On("FetchDue", ctx, mock.AnythingOfType("time.Time")).Return(due, test.fetchErr).Once()
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			mockRepo.On("FindByID", ctx, 1).Return(test.position, test.findErr).Once()
+			salaryMockRepo.On("FindEffective", ctx, 1, mock.AnythingOfType("time.Time")).Return(test.current, nil).Once()
+			mockRepo.On("UpdateByID", ctx, 1, &model.Position{Salary: 250000}).
+				Return(&model.Position{ID: 1, Name: "Manager", Salary: 250000}, test.updateErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Actor == model.AuditActorSystem && log.After["salary"] == float64(250000)
+			})).Return(nil, nil).Once()
+			salaryMockRepo.On("MarkApplied", ctx, 5, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+			count, err := useCase.ApplySalaryChanges(ctx)
+
+			assert.Equal(t, test.expectedCount, count)
+			assert.Equal(t, test.expectedErr, err)
+			if !test.expectUpdate {
+				mockRepo.AssertNotCalled(t, "UpdateByID", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if test.expectedErr != nil {
+				salaryMockRepo.AssertNotCalled(t, "MarkApplied", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -2,24 +2,165 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/response"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type transactionUsecase struct {
 	transactionRepository model.TransactionRepository
+	companyRepo           model.CompanyRepository
+	withdrawalRepo        model.WithdrawalRepository
+	payslipRepo           model.PayslipRepository
+	transactor            model.Transactor
 }
 
-func NewTransactionUsecase(transaction model.TransactionRepository) model.TransactionUsecase {
-	return &transactionUsecase{transactionRepository: transaction}
+func NewTransactionUsecase(transaction model.TransactionRepository, company model.CompanyRepository, withdrawal model.WithdrawalRepository,
+	payslip model.PayslipRepository, transactor model.Transactor) model.TransactionUsecase {
+	return &transactionUsecase{
+		transactionRepository: transaction,
+		companyRepo:           company,
+		withdrawalRepo:        withdrawal,
+		payslipRepo:           payslip,
+		transactor:            transactor,
+	}
 }
 
-func (t *transactionUsecase) Fetch(ctx context.Context, limit, offset int) ([]*model.Transaction, int, error) {
-	transations, err := t.transactionRepository.Fetch(ctx, limit, offset)
+func (t *transactionUsecase) Fetch(ctx context.Context, filter request.TransactionFilter) ([]*model.Transaction, *response.Meta, int, error) {
+	transations, err := t.transactionRepository.Fetch(ctx, filter)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	total, err := t.transactionRepository.Count(ctx, filter)
 	if err != nil {
-		return nil, http.StatusInternalServerError, err
+		return nil, nil, http.StatusInternalServerError, err
 	}
 
-	return transations, http.StatusOK, err
+	meta := &response.Meta{
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	return transations, meta, http.StatusOK, err
+
+}
+
+// Reverse undoes the ledger entry with id by writing an entry of the same
+// amount in the opposite direction, which moves the company balance back.
+// Reversing a salary withdrawal also reverses the tax and contributions paid
+// with it, frees its pay period so the employee can be paid again, and drops
+// its payslip.
+func (t *transactionUsecase) Reverse(ctx context.Context, id int, req request.ReversalRequest) (*model.Transaction, int, error) {
+	var reversal *model.Transaction
+
+	// A reversal of a credit that the balance cannot cover is kept as a
+	// rejected entry, like a rejected withdrawal.
+	var debitErr error
+
+	err := t.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Locking the company serialises reversals with every other balance
+		// change, so the same entry cannot be reversed twice concurrently.
+		if _, err := t.companyRepo.GetForUpdate(ctx); err != nil {
+			return err
+		}
+
+		original, err := t.transactionRepository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversedAt != nil {
+			return model.ErrAlreadyReversed
+		}
+
+		if !original.Reversible() {
+			return model.ErrNotReversible
+		}
+
+		reversal = reversalOf(original, req.Reason)
+
+		if original.Type == model.TransactionTypeDebit {
+			_, err = t.companyRepo.AddBalance(ctx, reversal)
+		} else {
+			err = t.companyRepo.DebitBalance(ctx, reversal)
+			if errors.Is(err, model.ErrInsufficientBalance) {
+				debitErr = err
+				return nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
 
+		if err := t.transactionRepository.MarkReversed(ctx, original.ID, now); err != nil {
+			return err
+		}
+
+		if original.Category == model.TransactionCategorySalaryWithdrawal {
+			withheld, err := t.transactionRepository.FetchUnreversed(ctx, withheldReferences(original.Reference))
+			if err != nil {
+				return err
+			}
+
+			// the tax and contributions were debited, so they are credited back
+			for _, entry := range withheld {
+				if _, err := t.companyRepo.AddBalance(ctx, reversalOf(entry, req.Reason)); err != nil {
+					return err
+				}
+
+				if err := t.transactionRepository.MarkReversed(ctx, entry.ID, now); err != nil {
+					return err
+				}
+			}
+
+			if err := t.withdrawalRepo.DeleteByTransaction(ctx, original.ID); err != nil {
+				return err
+			}
+
+			return t.payslipRepo.DeleteByTransaction(ctx, original.ID)
+		}
+
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusNotFound, err
+	}
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	if debitErr != nil {
+		return nil, http.StatusUnprocessableEntity, debitErr
+	}
+
+	return reversal, http.StatusOK, nil
+}
+
+// reversalOf is the ledger entry undoing original, for reason.
+func reversalOf(original *model.Transaction, reason string) *model.Transaction {
+	reference := original.Reference
+	if reference == "" {
+		reference = strconv.Itoa(original.ID)
+	}
+
+	return &model.Transaction{
+		Amount:       original.Amount,
+		Note:         fmt.Sprintf("Reversal of transaction %d: %s", original.ID, reason),
+		Category:     model.TransactionCategoryReversal,
+		Reference:    "REV-" + reference,
+		UserID:       original.UserID,
+		PositionID:   original.PositionID,
+		ReversalOfID: &original.ID,
+	}
 }
@@ -3,59 +3,329 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"self-payrol/model"
+	"self-payrol/request"
+	"self-payrol/response"
 	"self-payrol/usecase/mocks"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestFetchTransaction(t *testing.T) {
 	var mockRepo mocks.TransactionRepository
-	useCase := NewTransactionUsecase(&mockRepo)
+	useCase := NewTransactionUsecase(&mockRepo, nil, nil, nil, nil)
 	ctx := context.Background()
+	userID := 1
+	filter := request.TransactionFilter{
+		Type:   model.TransactionTypeDebit,
+		From:   "2022-10-01",
+		To:     "2022-10-31",
+		UserID: userID,
+		Sort:   "-created_at",
+		Limit:  10,
+		Offset: 1,
+	}
 	transactionData := &model.Transaction{
 		ID:        1,
 		Amount:    100000,
 		Note:      "user withdraw salary ",
 		Type:      "debit",
+		Status:    "completed",
+		Category:  "salary_withdrawal",
+		Reference: "SAL-2022-10-1",
+		UserID:    &userID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 	tests := []struct {
 		name           string
 		data           []*model.Transaction
-		status         int
 		err            error
+		total          int64
+		countErr       error
 		expectedResp   []*model.Transaction
+		expectedMeta   *response.Meta
 		expectedStatus int
 		expectedErr    error
 	}{
 		{
 			name:           "should fetch transactions successfully",
 			data:           []*model.Transaction{transactionData, transactionData},
-			status:         200,
+			total:          12,
 			expectedResp:   []*model.Transaction{transactionData, transactionData},
+			expectedMeta:   &response.Meta{Total: 12, Limit: 10, Offset: 1},
 			expectedStatus: 200,
 		},
 		{
 			name:           "should get some error",
-			status:         500,
 			err:            errors.New("some error"),
 			expectedStatus: 500,
 			expectedErr:    errors.New("some error"),
 		},
+		{
+			name:           "should get some error while count transactions",
+			data:           []*model.Transaction{transactionData, transactionData},
+			countErr:       errors.New("some error"),
+			expectedStatus: 500,
+			expectedErr:    errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockRepo.On("Fetch", ctx, filter).Return(test.data, test.err).Once()
+			if test.err == nil {
+				mockRepo.On("Count", ctx, filter).Return(test.total, test.countErr).Once()
+			}
+			res, meta, status, err := useCase.Fetch(ctx, filter)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedMeta, meta)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestReverseTransaction(t *testing.T) {
+	ctx := context.Background()
+	userID, positionID := 1, 1
+	req := request.ReversalRequest{Reason: "paid twice"}
+	reversedAt := time.Now()
+	companyData := &model.Company{ID: 1, Name: "Test Company", Balance: 200000}
+	withdrawal := &model.Transaction{
+		ID:         7,
+		Amount:     100000,
+		Note:       "user withdraw salary ",
+		Type:       model.TransactionTypeDebit,
+		Status:     model.TransactionStatusCompleted,
+		Category:   model.TransactionCategorySalaryWithdrawal,
+		Reference:  "SAL-2022-10-1",
+		UserID:     &userID,
+		PositionID: &positionID,
+	}
+	tax := &model.Transaction{
+		ID:         11,
+		Amount:     10000,
+		Note:       "user income tax withheld",
+		Type:       model.TransactionTypeDebit,
+		Status:     model.TransactionStatusCompleted,
+		Category:   model.TransactionCategoryTax,
+		Reference:  "TAX-2022-10-1",
+		UserID:     &userID,
+		PositionID: &positionID,
+	}
+	employeeContributions := &model.Transaction{
+		ID:         12,
+		Amount:     1000,
+		Note:       "user employee contributions",
+		Type:       model.TransactionTypeDebit,
+		Status:     model.TransactionStatusCompleted,
+		Category:   model.TransactionCategoryEmployeeContribution,
+		Reference:  "BPJS-EE-2022-10-1",
+		UserID:     &userID,
+		PositionID: &positionID,
+	}
+	topup := &model.Transaction{
+		ID:       8,
+		Amount:   500000,
+		Note:     "Topup balance company",
+		Type:     model.TransactionsTypeCredit,
+		Status:   model.TransactionStatusCompleted,
+		Category: model.TransactionCategoryTopup,
+	}
+	reversed := &model.Transaction{
+		ID:         7,
+		Amount:     100000,
+		Type:       model.TransactionTypeDebit,
+		Status:     model.TransactionStatusCompleted,
+		Category:   model.TransactionCategorySalaryWithdrawal,
+		ReversedAt: &reversedAt,
+	}
+	rejected := &model.Transaction{
+		ID:       9,
+		Amount:   100000,
+		Type:     model.TransactionTypeDebit,
+		Status:   model.TransactionStatusRejected,
+		Category: model.TransactionCategorySalaryWithdrawal,
+	}
+	reconciliation := &model.Transaction{
+		ID:       10,
+		Amount:   50000,
+		Type:     model.TransactionsTypeCredit,
+		Status:   model.TransactionStatusCompleted,
+		Category: model.TransactionCategoryReconciliation,
+	}
+	tests := []struct {
+		name             string
+		original         *model.Transaction
+		withheld         []*model.Transaction
+		companyErr       error
+		findErr          error
+		balanceErr       error
+		markErr          error
+		deleteErr        error
+		deletePayslipErr error
+		expectedResp     *model.Transaction
+		expectedStatus   int
+		expectedErr      error
+	}{
+		{
+			name:     "should reverse salary withdrawal successfully",
+			original: withdrawal,
+			withheld: []*model.Transaction{tax, employeeContributions},
+			expectedResp: &model.Transaction{
+				Amount:       100000,
+				Note:         "Reversal of transaction 7: paid twice",
+				Category:     model.TransactionCategoryReversal,
+				Reference:    "REV-SAL-2022-10-1",
+				UserID:       &userID,
+				PositionID:   &positionID,
+				ReversalOfID: &withdrawal.ID,
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:     "should reverse topup successfully",
+			original: topup,
+			expectedResp: &model.Transaction{
+				Amount:       500000,
+				Note:         "Reversal of transaction 8: paid twice",
+				Category:     model.TransactionCategoryReversal,
+				Reference:    "REV-8",
+				ReversalOfID: &topup.ID,
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:           "should get not found error",
+			findErr:        gorm.ErrRecordNotFound,
+			expectedStatus: 404,
+			expectedErr:    gorm.ErrRecordNotFound,
+		},
+		{
+			name:           "should get some error while lock company",
+			companyErr:     errors.New("company data not found"),
+			expectedStatus: 422,
+			expectedErr:    errors.New("company data not found"),
+		},
+		{
+			name:           "should get already reversed error",
+			original:       reversed,
+			expectedStatus: 422,
+			expectedErr:    model.ErrAlreadyReversed,
+		},
+		{
+			name:           "should get not reversible error for rejected transaction",
+			original:       rejected,
+			expectedStatus: 422,
+			expectedErr:    model.ErrNotReversible,
+		},
+		{
+			name:           "should get not reversible error for reconciliation entry",
+			original:       reconciliation,
+			expectedStatus: 422,
+			expectedErr:    model.ErrNotReversible,
+		},
+		{
+			name:           "should get not reversible error for tax paid with a salary",
+			original:       tax,
+			expectedStatus: 422,
+			expectedErr:    model.ErrNotReversible,
+		},
+		{
+			name:           "should get insufficient balance error while reverse topup",
+			original:       topup,
+			balanceErr:     model.ErrInsufficientBalance,
+			expectedStatus: 422,
+			expectedErr:    model.ErrInsufficientBalance,
+		},
+		{
+			name:           "should get some error while mark reversed",
+			original:       withdrawal,
+			markErr:        model.ErrAlreadyReversed,
+			expectedStatus: 422,
+			expectedErr:    model.ErrAlreadyReversed,
+		},
+		{
+			name:           "should get some error while delete withdrawal",
+			original:       withdrawal,
+			deleteErr:      errors.New("some error"),
+			expectedStatus: 422,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:             "should get some error while delete payslip",
+			original:         withdrawal,
+			deletePayslipErr: errors.New("some error"),
+			expectedStatus:   422,
+			expectedErr:      errors.New("some error"),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			mockRepo.On("Fetch", ctx, 10, 1).Return(test.data, test.err).Once()
-			res, status, err := useCase.Fetch(ctx, 10, 1)
+			var (
+				transactionMockRepo mocks.TransactionRepository
+				companyMockRepo     mocks.CompanyRepository
+				withdrawalMockRepo  mocks.WithdrawalRepository
+				payslipMockRepo     mocks.PayslipRepository
+				transactorMock      mocks.Transactor
+			)
+			useCase := NewTransactionUsecase(&transactionMockRepo, &companyMockRepo, &withdrawalMockRepo, &payslipMockRepo, &transactorMock)
+
+			id := 7
+			if test.original != nil {
+				id = test.original.ID
+			}
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			companyMockRepo.On("GetForUpdate", ctx).Return(companyData, test.companyErr).Once()
+			transactionMockRepo.On("FindByID", ctx, id).Return(test.original, test.findErr).Once()
+			companyMockRepo.On("AddBalance", ctx, mock.AnythingOfType("*model.Transaction")).Return(companyData, test.balanceErr).
+				Times(1 + len(test.withheld))
+			companyMockRepo.On("DebitBalance", ctx, mock.AnythingOfType("*model.Transaction")).Return(test.balanceErr).Once()
+			transactionMockRepo.On("MarkReversed", ctx, id, mock.AnythingOfType("time.Time")).Return(test.markErr).Once()
+			transactionMockRepo.On("FetchUnreversed", ctx, []string{"TAX-2022-10-1", "BPJS-EE-2022-10-1", "BPJS-ER-2022-10-1"}).
+				Return(test.withheld, nil).Once()
+			for _, entry := range test.withheld {
+				transactionMockRepo.On("MarkReversed", ctx, entry.ID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+			}
+			withdrawalMockRepo.On("DeleteByTransaction", ctx, id).Return(test.deleteErr).Once()
+			payslipMockRepo.On("DeleteByTransaction", ctx, id).Return(test.deletePayslipErr).Once()
+
+			res, status, err := useCase.Reverse(ctx, id, req)
 
 			assert.Equal(t, test.expectedResp, res)
 			assert.Equal(t, test.expectedStatus, status)
 			assert.Equal(t, test.expectedErr, err)
+			// the tax and contributions are credited back with the salary
+			for _, entry := range test.withheld {
+				companyMockRepo.AssertCalled(t, "AddBalance", ctx, &model.Transaction{
+					Amount:       entry.Amount,
+					Note:         fmt.Sprintf("Reversal of transaction %d: paid twice", entry.ID),
+					Category:     model.TransactionCategoryReversal,
+					Reference:    "REV-" + entry.Reference,
+					UserID:       &userID,
+					PositionID:   &positionID,
+					ReversalOfID: &entry.ID,
+				})
+				transactionMockRepo.AssertCalled(t, "MarkReversed", ctx, entry.ID, mock.AnythingOfType("time.Time"))
+			}
+			if test.original == reconciliation || test.original == tax {
+				companyMockRepo.AssertNotCalled(t, "AddBalance", mock.Anything, mock.Anything)
+				companyMockRepo.AssertNotCalled(t, "DebitBalance", mock.Anything, mock.Anything)
+			}
+			if test.expectedErr == nil && test.original.Category != model.TransactionCategorySalaryWithdrawal {
+				withdrawalMockRepo.AssertNotCalled(t, "DeleteByTransaction", ctx, id)
+				payslipMockRepo.AssertNotCalled(t, "DeleteByTransaction", ctx, id)
+			}
 		})
 	}
 }
@@ -6,36 +6,133 @@ import (
 	"gorm.io/gorm"
 	"self-payrol/model"
 	"self-payrol/request"
+	"time"
 )
 
 type userUsecase struct {
+	*payroll
 	userRepository model.UserRepository
 	positionRepo   model.PositionRepository
-	companyRepo    model.CompanyRepository
+	transactor     model.Transactor
+	attemptRepo    model.SecretAttemptRepository
+	auditLogRepo   model.AuditLogRepository
+	lockout        model.LockoutPolicy
 }
 
-func NewUserUsecase(user model.UserRepository, post model.PositionRepository, company model.CompanyRepository) model.UserUsecase {
-	return &userUsecase{userRepository: user, positionRepo: post, companyRepo: company}
+func NewUserUsecase(user model.UserRepository, post model.PositionRepository, salary model.SalaryVersionRepository,
+	component model.PayComponentRepository, company model.CompanyRepository, withdrawal model.WithdrawalRepository,
+	payslip model.PayslipRepository, transactor model.Transactor, attempt model.SecretAttemptRepository,
+	auditLog model.AuditLogRepository, lockout model.LockoutPolicy, taxCalculator model.TaxCalculator,
+	contributions []model.ContributionRule) model.UserUsecase {
+	return &userUsecase{
+		payroll: &payroll{
+			salaryRepo:     salary,
+			componentRepo:  component,
+			companyRepo:    company,
+			withdrawalRepo: withdrawal,
+			payslipRepo:    payslip,
+			taxCalculator:  taxCalculator,
+			contributions:  contributions,
+		},
+		userRepository: user,
+		positionRepo:   post,
+		transactor:     transactor,
+		attemptRepo:    attempt,
+		auditLogRepo:   auditLog,
+		lockout:        lockout,
+	}
 }
 
-func (p *userUsecase) WithdrawSalary(ctx context.Context, req *request.WithdrawRequest) error {
-	user, err := p.userRepository.FindByID(ctx, req.ID)
+// WithdrawSalary pays the employee their net pay for the current pay period,
+// the base salary plus allowances minus deductions, income tax and employee
+// contributions, and returns its breakdown. The tax and the contributions of
+// the employee and of the company are debited as entries of their own, and a
+// payslip of the payout is kept. Nothing is paid when the balance cannot cover
+// all of them, a rejected entry of their total is kept instead.
+func (p *userUsecase) WithdrawSalary(ctx context.Context, req *request.WithdrawRequest) (*model.PayBreakdown, error) {
+	user, err := p.VerifySecret(ctx, req.ID, req.SecretID, req.IP)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if user.SecretID != req.SecretID {
-		return errors.New("secret id not valid")
+	notes := user.Name + " withdraw salary "
+
+	// A rejected debit is still committed so its ledger entry is kept, the
+	// error is only returned once the transaction is done.
+	var debitErr error
+
+	var pay *model.PayBreakdown
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Locking the company first serialises withdrawals, so the period
+		// check below cannot race with another withdrawal of the same user.
+		company, err := p.companyRepo.GetForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		period := model.NewPayPeriod(company.PayPeriod, time.Now())
+
+		_, err = p.withdrawalRepo.FindOverlapping(ctx, user.ID, period)
+		if err == nil {
+			return model.ErrAlreadyWithdrawn
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		pay, err = p.payFor(ctx, user, company.PayPeriod, period)
+		if err != nil {
+			return err
+		}
+
+		if pay.NetPay <= 0 {
+			return model.ErrNoNetPay
+		}
+
+		transaction := salaryEntry(user, period, pay, notes)
+
+		// The balance has to cover the tax and contributions paid with the
+		// salary as well. A withdrawal it cannot cover is kept as a single
+		// rejected entry of its whole cost.
+		if !company.CanDebit(pay.Cost()) {
+			transaction.Amount = pay.Cost()
+		}
+
+		err = p.companyRepo.DebitBalance(ctx, transaction)
+		if errors.Is(err, model.ErrInsufficientBalance) {
+			debitErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return p.recordPayout(ctx, user, company, period, pay, transaction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	notes := user.Name + " withdraw salary "
+	if debitErr != nil {
+		return nil, debitErr
+	}
 
-	err = p.companyRepo.DebitBalance(ctx, user.Position.Salary, notes)
+	return pay, nil
+}
+
+func (p *userUsecase) FetchWithdrawals(ctx context.Context, id, limit, offset int) ([]*model.Withdrawal, error) {
+	_, err := p.userRepository.FindByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	withdrawals, err := p.withdrawalRepo.FetchByUser(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return withdrawals, nil
 }
 
 func (p *userUsecase) GetByID(ctx context.Context, id int) (*model.User, error) {
@@ -59,29 +156,43 @@ func (p *userUsecase) FetchUser(ctx context.Context, limit, offset int) ([]*mode
 }
 
 func (p *userUsecase) DestroyUser(ctx context.Context, id int) error {
-	err := p.userRepository.Delete(ctx, id)
+	existing, err := p.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.userRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionDelete, model.AuditEntityUser, id, existing, nil)
+	})
 }
 
 func (p *userUsecase) EditUser(ctx context.Context, id int, req *request.UserRequest) (*model.User, error) {
-	_, err := p.userRepository.FindByID(ctx, id)
+	existing, err := p.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := p.userRepository.UpdateByID(ctx, id, &model.User{
-		SecretID:   req.SecretID,
-		Name:       req.Name,
-		Email:      req.Email,
-		Phone:      req.Phone,
-		Address:    req.Address,
-		PositionID: req.PositionID,
-	})
+	var user *model.User
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err = p.userRepository.UpdateByID(ctx, id, &model.User{
+			Name:       req.Name,
+			Email:      req.Email,
+			Phone:      req.Phone,
+			Address:    req.Address,
+			PositionID: req.PositionID,
+			TaxStatus:  req.TaxStatus,
+		})
+		if err != nil {
+			return err
+		}
 
+		return audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityUser, id, existing, user)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -91,12 +202,12 @@ func (p *userUsecase) EditUser(ctx context.Context, id int, req *request.UserReq
 
 func (p *userUsecase) StoreUser(ctx context.Context, req *request.UserRequest) (*model.User, error) {
 	newUser := &model.User{
-		SecretID:   req.SecretID,
 		Name:       req.Name,
 		Email:      req.Email,
 		Phone:      req.Phone,
 		Address:    req.Address,
 		PositionID: req.PositionID,
+		TaxStatus:  req.TaxStatus,
 	}
 
 	_, err := p.positionRepo.FindByID(ctx, req.PositionID)
@@ -108,11 +219,332 @@ func (p *userUsecase) StoreUser(ctx context.Context, req *request.UserRequest) (
 		return nil, err
 	}
 
-	user, err := p.userRepository.Create(ctx, newUser)
+	if err := newUser.SetSecret(req.SecretID); err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err = p.userRepository.Create(ctx, newUser)
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionCreate, model.AuditEntityUser, user.ID, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ResetSecret replaces the credential of the employee with id. The old secret
+// is never read, so it can be rotated without anyone knowing it.
+func (p *userUsecase) ResetSecret(ctx context.Context, id int, req *request.SecretResetRequest) error {
+	_, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	user := new(model.User)
+	if err := user.SetSecret(req.SecretID); err != nil {
+		return err
+	}
+
+	return p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := p.userRepository.UpdateByID(ctx, id, user); err != nil {
+			return err
+		}
+
+		// The secret itself is left out of the audit log, only the reset is
+		// recorded.
+		return audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityUser, id, nil,
+			map[string]interface{}{"secret_id": "reset"})
+	})
+}
 
+// NextWithdrawal reports when the employee with id can withdraw their salary.
+// An employee who was paid in the current pay period is eligible again when
+// the next one starts.
+func (p *userUsecase) NextWithdrawal(ctx context.Context, id int) (*model.WithdrawalEligibility, error) {
+	user, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := p.companyRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	period := model.NewPayPeriod(company.PayPeriod, now)
+	eligibility := &model.WithdrawalEligibility{
+		Eligible:       true,
+		Period:         period,
+		NextEligibleAt: now,
+	}
+
+	_, err = p.withdrawalRepo.FindOverlapping(ctx, user.ID, period)
+	if err == nil {
+		eligibility.Eligible = false
+		eligibility.Period = model.NewPayPeriod(company.PayPeriod, period.End)
+		eligibility.NextEligibleAt = period.End
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	eligibility.Pay, err = p.payFor(ctx, user, company.PayPeriod, eligibility.Period)
+	if err != nil {
+		return nil, err
+	}
+	eligibility.Salary = eligibility.Pay.BaseSalary
+
+	if user.Locked(now) {
+		eligibility.Eligible = false
+		eligibility.LockedUntil = user.LockedUntil
+		if user.LockedUntil.After(eligibility.NextEligibleAt) {
+			eligibility.NextEligibleAt = *user.LockedUntil
+		}
+	}
+
+	return eligibility, nil
+}
+
+// Unlock lifts the withdrawal lock of the employee with id and clears their
+// failed secret id counter.
+func (p *userUsecase) Unlock(ctx context.Context, id int) (*model.User, error) {
+	existing, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.userRepository.ResetFailedSecrets(ctx, id); err != nil {
+			return err
+		}
+
+		user, err = p.userRepository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityUser, id, existing, user)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
+
+// VerifySecret checks the secret id of the employee with id, sent from the
+// client address ip, against the lockout policy. Clients failing too often are
+// refused before the secret is looked at, and an employee is locked once their
+// secret id fails too many times in a row. An id matching no employee counts
+// as a failure of the client too, so guessing ids is throttled as well.
+func (p *userUsecase) VerifySecret(ctx context.Context, id int, secretID, ip string) (*model.User, error) {
+	now := time.Now()
+
+	failures, err := p.attemptRepo.CountByIP(ctx, ip, now.Add(-p.lockout.Cooldown))
+	if err != nil {
+		return nil, err
+	}
+
+	if failures >= int64(p.lockout.IPMaxAttempts) {
+		return nil, model.ErrTooManyAttempts
+	}
+
+	user, err := p.userRepository.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := p.attemptRepo.Create(ctx, &model.SecretAttempt{IP: ip}); err != nil {
+			return nil, err
+		}
+
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Locked(now) {
+		return nil, model.ErrEmployeeLocked
+	}
+
+	// an expired lock starts the count over
+	if user.LockedUntil != nil {
+		if err := p.userRepository.ResetFailedSecrets(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		user.FailedSecretAttempts, user.LockedUntil = 0, nil
+	}
+
+	if !user.CheckSecret(secretID) {
+		if err := p.attemptRepo.Create(ctx, &model.SecretAttempt{UserID: user.ID, IP: ip}); err != nil {
+			return nil, err
+		}
+
+		if err := p.userRepository.RegisterFailedSecret(ctx, user.ID, p.lockout.MaxAttempts,
+			now.Add(p.lockout.Cooldown)); err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New("secret id not valid")
+	}
+
+	if user.FailedSecretAttempts > 0 {
+		if err := p.userRepository.ResetFailedSecrets(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Secrets stored before hashing was introduced are hashed on their
+	// first successful use.
+	if !user.SecretHashed() {
+		hashed := new(model.User)
+		if err := hashed.SetSecret(secretID); err != nil {
+			return nil, err
+		}
+
+		if _, err := p.userRepository.UpdateByID(ctx, user.ID, hashed); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// SetBaseSalary overrides the position salary of the employee with id, or
+// removes the override when req.BaseSalary is nil.
+func (p *userUsecase) SetBaseSalary(ctx context.Context, id int, req *request.BaseSalaryRequest) (*model.User, error) {
+	existing, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.userRepository.UpdateBaseSalary(ctx, id, req.BaseSalary); err != nil {
+			return err
+		}
+
+		user, err = p.userRepository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionUpdate, model.AuditEntityUser, id, existing, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (p *userUsecase) FetchPayComponents(ctx context.Context, id int) ([]*model.PayComponent, error) {
+	_, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.componentRepo.FetchByUser(ctx, id)
+}
+
+// AddPayComponent attaches a recurring allowance or deduction to every salary
+// of the employee with id.
+func (p *userUsecase) AddPayComponent(ctx context.Context, id int, req *request.PayComponentRequest) (*model.PayComponent, error) {
+	_, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var component *model.PayComponent
+
+	err = p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		component, err = p.componentRepo.Create(ctx, &model.PayComponent{
+			UserID: id,
+			Name:   req.Name,
+			Type:   req.Type,
+			Amount: req.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionCreate, model.AuditEntityPayComponent, component.ID, nil, component)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+func (p *userUsecase) RemovePayComponent(ctx context.Context, id, componentID int) error {
+	_, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := p.componentRepo.Delete(ctx, id, componentID); err != nil {
+			return err
+		}
+
+		return audit(ctx, p.auditLogRepo, model.AuditActionDelete, model.AuditEntityPayComponent, componentID,
+			map[string]interface{}{"user_id": id}, nil)
+	})
+}
+
+// TaxSummary adds up the salaries paid to the employee with id for the pay
+// periods starting in year, the contributions counting towards their income
+// tax and the income tax withheld from them.
+func (p *userUsecase) TaxSummary(ctx context.Context, id, year int) (*model.TaxSummary, error) {
+	user, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+
+	withdrawals, err := p.withdrawalRepo.FetchByUserBetween(ctx, id, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.TaxSummary{
+		UserID:      id,
+		Year:        year,
+		TaxStatus:   user.TaxStatus,
+		Withdrawals: withdrawals,
+	}
+	for _, withdrawal := range withdrawals {
+		summary.GrossIncome += withdrawal.GrossPay
+		summary.TaxableBenefits += withdrawal.TaxableBenefits
+		summary.DeductibleContributions += withdrawal.DeductibleContributions
+		summary.Withheld += withdrawal.Tax
+	}
+
+	summary.AnnualTax = p.taxCalculator.AnnualTax(user.TaxStatus, summary.GrossIncome+summary.TaxableBenefits,
+		summary.DeductibleContributions)
+	summary.Outstanding = summary.AnnualTax - summary.Withheld
+
+	return summary, nil
+}
+
+// Payslip returns the payslip of the salary paid to the employee with id for
+// the pay period keyed period.
+func (p *userUsecase) Payslip(ctx context.Context, id int, period string) (*model.Payslip, error) {
+	_, err := p.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.payslipRepo.FindByUserAndPeriod(ctx, id, period)
+}
@@ -10,16 +10,20 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
 func TestGetUserByID(t *testing.T) {
 	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
+		userMockRepo       mocks.UserRepository
+		positionMockRepo   mocks.PositionRepository
+		companyMockRepo    mocks.CompanyRepository
+		withdrawalMockRepo mocks.WithdrawalRepository
+		transactorMock     mocks.Transactor
 	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
+	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo, nil,
+		&transactorMock, nil, nil, model.LockoutPolicy{}, nil, nil)
 	ctx := context.Background()
 	userData := &model.User{
 		ID:         1,
@@ -74,11 +78,14 @@ func TestGetUserByID(t *testing.T) {
 
 func TestFetchUser(t *testing.T) {
 	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
+		userMockRepo       mocks.UserRepository
+		positionMockRepo   mocks.PositionRepository
+		companyMockRepo    mocks.CompanyRepository
+		withdrawalMockRepo mocks.WithdrawalRepository
+		transactorMock     mocks.Transactor
 	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
+	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo, nil,
+		&transactorMock, nil, nil, model.LockoutPolicy{}, nil, nil)
 	ctx := context.Background()
 	userData := &model.User{
 		ID:         1,
@@ -132,17 +139,13 @@ func TestFetchUser(t *testing.T) {
 }
 
 func TestDestroyUser(t *testing.T) {
-	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
-	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
 	ctx := context.Background()
 	tests := []struct {
 		name        string
 		id          int
+		findErr     error
 		err         error
+		auditErr    error
 		expectedErr error
 	}{
 		{
@@ -155,11 +158,38 @@ func TestDestroyUser(t *testing.T) {
 			err:         errors.New("some error"),
 			expectedErr: errors.New("some error"),
 		},
+		{
+			name:        "should get some error while find user",
+			id:          1,
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while write audit log",
+			id:          1,
+			auditErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo   mocks.UserRepository
+				transactorMock mocks.Transactor
+				auditMockRepo  mocks.AuditLogRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, &transactorMock, nil, &auditMockRepo,
+				model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, test.id).Return(&model.User{ID: 1, Name: "user"}, test.findErr).Once()
 			userMockRepo.On("Delete", ctx, test.id).Return(test.err).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Action == model.AuditActionDelete && log.EntityType == model.AuditEntityUser &&
+					log.Before["name"] == "user" && len(log.After) == 0
+			})).Return(nil, test.auditErr).Once()
 			err := useCase.DestroyUser(ctx, test.id)
 			assert.Equal(t, test.expectedErr, err)
 		})
@@ -168,11 +198,15 @@ func TestDestroyUser(t *testing.T) {
 
 func TestEditUser(t *testing.T) {
 	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
+		userMockRepo       mocks.UserRepository
+		positionMockRepo   mocks.PositionRepository
+		companyMockRepo    mocks.CompanyRepository
+		withdrawalMockRepo mocks.WithdrawalRepository
+		transactorMock     mocks.Transactor
+		auditMockRepo      mocks.AuditLogRepository
 	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
+	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo, nil,
+		&transactorMock, nil, &auditMockRepo, model.LockoutPolicy{}, nil, nil)
 	ctx := context.Background()
 	userData := &model.User{
 		ID:         1,
@@ -250,15 +284,19 @@ func TestEditUser(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
 			userMockRepo.On("FindByID", ctx, test.id).Return(test.data, test.findUserRepoErr).Once()
 			userMockRepo.On("UpdateByID", ctx, test.id, &model.User{
-				SecretID:   test.req.SecretID,
 				Name:       test.req.Name,
 				Email:      test.req.Email,
 				Phone:      test.req.Phone,
 				Address:    test.req.Address,
 				PositionID: test.req.PositionID,
 			}).Return(test.data, test.updateUserRepoErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Action == model.AuditActionUpdate && log.EntityID == test.id
+			})).Return(nil, nil).Once()
 			res, err := useCase.EditUser(ctx, test.id, test.req)
 
 			assert.Equal(t, test.expectedResp, res)
@@ -269,11 +307,15 @@ func TestEditUser(t *testing.T) {
 
 func TestStoreUser(t *testing.T) {
 	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
+		userMockRepo       mocks.UserRepository
+		positionMockRepo   mocks.PositionRepository
+		companyMockRepo    mocks.CompanyRepository
+		withdrawalMockRepo mocks.WithdrawalRepository
+		transactorMock     mocks.Transactor
+		auditMockRepo      mocks.AuditLogRepository
 	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
+	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo, nil,
+		&transactorMock, nil, &auditMockRepo, model.LockoutPolicy{}, nil, nil)
 	ctx := context.Background()
 	userData := &model.User{
 		ID:         1,
@@ -361,15 +403,23 @@ func TestStoreUser(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
 			positionMockRepo.On("FindByID", ctx, test.data.PositionID).Return(test.data.Position, test.findPositionRepoErr).Once()
-			userMockRepo.On("Create", ctx, &model.User{
-				SecretID:   test.req.SecretID,
-				Name:       test.req.Name,
-				Email:      test.req.Email,
-				Phone:      test.req.Phone,
-				Address:    test.req.Address,
-				PositionID: test.req.PositionID,
-			}).Return(test.data, test.createUserRepoErr).Once()
+			userMockRepo.On("Create", ctx, mock.MatchedBy(func(user *model.User) bool {
+				return user.SecretHashed() && user.CheckSecret(test.req.SecretID) && *user == model.User{
+					SecretID:   user.SecretID,
+					Name:       test.req.Name,
+					Email:      test.req.Email,
+					Phone:      test.req.Phone,
+					Address:    test.req.Address,
+					PositionID: test.req.PositionID,
+				}
+			})).Return(test.data, test.createUserRepoErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				_, secret := log.After["secret_id"]
+				return log.Action == model.AuditActionCreate && log.EntityID == test.data.ID && !secret
+			})).Return(nil, nil).Once()
 			res, err := useCase.StoreUser(ctx, test.req)
 
 			assert.Equal(t, test.expectedResp, res)
@@ -378,13 +428,29 @@ func TestStoreUser(t *testing.T) {
 	}
 }
 
+// flatTax withholds a tenth of every salary and of the benefits taxed with it.
+type flatTax struct{}
+
+func (flatTax) Withhold(salary model.TaxableSalary) int {
+	return (salary.GrossPay + model.TaxableBenefits(salary.Contributions)) / 10
+}
+
+func (flatTax) AnnualTax(taxStatus string, grossIncome, deductions int) int {
+	return (grossIncome - deductions) / 10
+}
+
+var contributionRules = []model.ContributionRule{
+	{Name: model.ContributionBPJSKesehatan, EmployeeRate: 100, EmployerRate: 400, WageCap: 120000},
+}
+
+// withheld takes the contributions and the flat tax off a monthly salary.
+func withheld(pay *model.PayBreakdown) *model.PayBreakdown {
+	pay.Contribute(contributionRules, model.PayPeriodMonthly)
+	pay.Withhold((pay.GrossPay + model.TaxableBenefits(pay.Contributions)) / 10)
+	return pay
+}
+
 func TestWithdrawSalary(t *testing.T) {
-	var (
-		userMockRepo     mocks.UserRepository
-		positionMockRepo mocks.PositionRepository
-		companyMockRepo  mocks.CompanyRepository
-	)
-	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &companyMockRepo)
 	ctx := context.Background()
 	userData := &model.User{
 		ID:         1,
@@ -404,30 +470,87 @@ func TestWithdrawSalary(t *testing.T) {
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	legacyUserData := *userData
+	assert.NoError(t, userData.SetSecret("asdjksakdas"))
+	baseSalary := 150000
+	overriddenUserData := *userData
+	overriddenUserData.BaseSalary = &baseSalary
+	companyData := &model.Company{
+		ID:        1,
+		Name:      "Test Company",
+		Address:   "Cempaka St.",
+		Balance:   200000,
+		PayPeriod: model.PayPeriodMonthly,
+	}
+	lockout := model.LockoutPolicy{MaxAttempts: 3, IPMaxAttempts: 10, Cooldown: time.Minute}
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	withdrawalData := &model.Withdrawal{
+		UserID:      userData.ID,
+		Period:      period.Key,
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+		Amount:      120000,
+	}
 	tests := []struct {
-		name           string
-		req            *request.WithdrawRequest
-		data           *model.User
-		userRepoErr    error
-		companyRepoErr error
-		expectedErr    error
+		name                string
+		req                 *request.WithdrawRequest
+		user                *model.User
+		userRepoErr         error
+		rehashErr           error
+		companyRepoErr      error
+		shortBalance        bool
+		raisedMidPeriod     bool
+		withdrawal          *model.Withdrawal
+		findWithdrawalErr   error
+		salaryErr           error
+		salary              int
+		components          []*model.PayComponent
+		componentErr        error
+		yearWithdrawalsErr  error
+		debitErr            error
+		createWithdrawalErr error
+		createPayslipErr    error
+		taxDebitErr         error
+		employerDebitErr    error
+		expectedPay         *model.PayBreakdown
+		expectedErr         error
 	}{
 		{
 			name: "should get some error while add debit balance",
 			req: &request.WithdrawRequest{
 				ID:       1,
-				SecretID: userData.SecretID,
+				SecretID: "asdjksakdas",
 			},
-			data:           userData,
-			companyRepoErr: errors.New("some error"),
-			expectedErr:    errors.New("some error"),
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			debitErr:          errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+		{
+			name: "should get insufficient balance error",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			debitErr:          model.ErrInsufficientBalance,
+			expectedErr:       model.ErrInsufficientBalance,
+		},
+		{
+			name: "should keep one rejected entry when the balance cannot cover the whole cost",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			shortBalance:      true,
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			debitErr:          model.ErrInsufficientBalance,
+			expectedErr:       model.ErrInsufficientBalance,
 		},
 		{
 			name: "should get some error while find user",
 			req: &request.WithdrawRequest{
 				ID: 1,
 			},
-			data:        userData,
 			userRepoErr: errors.New("some error"),
 			expectedErr: errors.New("some error"),
 		},
@@ -437,25 +560,1039 @@ func TestWithdrawSalary(t *testing.T) {
 				ID:       1,
 				SecretID: "xxx-xxx",
 			},
-			data:        userData,
+			expectedErr: errors.New("secret id not valid"),
+		},
+		{
+			name: "should get some error while lock company",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			companyRepoErr: errors.New("company data not found"),
+			expectedErr:    errors.New("company data not found"),
+		},
+		{
+			name: "should get already withdrawn error in the same period",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			withdrawal:  withdrawalData,
+			expectedErr: model.ErrAlreadyWithdrawn,
+		},
+		{
+			name: "should get some error while find withdrawal",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+		{
+			name: "should get some error while create withdrawal",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr:   gorm.ErrRecordNotFound,
+			createWithdrawalErr: errors.New("some error"),
+			expectedErr:         errors.New("some error"),
+		},
+		{
+			name: "should hash a plaintext secret id on withdraw",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			user:              &legacyUserData,
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			expectedPay:       withheld(model.NewPayBreakdown(120000, nil)),
+		},
+		{
+			name: "should get some error while hash a plaintext secret id",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			user:        &legacyUserData,
+			rehashErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name: "should get some error while plaintext secret id invalid",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "xxx-xxx",
+			},
+			user:        &legacyUserData,
 			expectedErr: errors.New("secret id not valid"),
 		},
 		{
 			name: "should withdraw salary successfully",
 			req: &request.WithdrawRequest{
 				ID:       1,
-				SecretID: userData.SecretID,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			expectedPay:       withheld(model.NewPayBreakdown(120000, nil)),
+		},
+		{
+			name: "should withdraw net pay of allowances and deductions",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			components: []*model.PayComponent{
+				{Name: "Transport", Type: model.PayComponentAllowance, Amount: 30000},
+				{Name: "Loan", Type: model.PayComponentDeduction, Amount: 10000},
+			},
+			expectedPay: &model.PayBreakdown{
+				BaseSalary:      120000,
+				Allowances:      []model.PayLine{{Name: "Transport", Amount: 30000}},
+				Deductions:      []model.PayLine{{Name: "Loan", Amount: 10000}},
+				GrossPay:        150000,
+				TotalDeductions: 10000,
+				Tax:             15480,
+				Contributions: []model.Contribution{
+					{Name: model.ContributionBPJSKesehatan, Wage: 120000, Employee: 1200, Employer: 4800},
+				},
+				EmployeeContributions: 1200,
+				EmployerContributions: 4800,
+				NetPay:                123320,
+			},
+		},
+		{
+			name: "should withdraw the salary in effect at the cut-off despite a raise later in the period",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			raisedMidPeriod:   true,
+			expectedPay:       withheld(model.NewPayBreakdown(120000, nil)),
+		},
+		{
+			name: "should withdraw base salary of the employee over the position salary",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			user:              &overriddenUserData,
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			salary:            150000,
+			expectedPay:       withheld(model.NewPayBreakdown(150000, nil)),
+		},
+		{
+			name: "should refuse when deductions take the whole salary",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			components: []*model.PayComponent{
+				{Name: "Loan", Type: model.PayComponentDeduction, Amount: 120000},
+			},
+			expectedErr: model.ErrNoNetPay,
+		},
+		{
+			name: "should roll back when the balance cannot cover the tax",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			taxDebitErr:       model.ErrInsufficientBalance,
+			expectedErr:       model.ErrInsufficientBalance,
+		},
+		{
+			name: "should get some error while create payslip",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			createPayslipErr:  errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+		{
+			name: "should roll back when the balance cannot cover the employer contributions",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			employerDebitErr:  model.ErrInsufficientBalance,
+			expectedErr:       model.ErrInsufficientBalance,
+		},
+		{
+			name: "should get some error while fetch earlier withdrawals of the year",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr:  gorm.ErrRecordNotFound,
+			yearWithdrawalsErr: errors.New("some error"),
+			expectedErr:        errors.New("some error"),
+		},
+		{
+			name: "should get some error while fetch pay components",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			componentErr:      errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+		{
+			name: "should withdraw the position salary without salary history",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			salaryErr:         gorm.ErrRecordNotFound,
+			salary:            userData.Position.Salary,
+			expectedPay:       withheld(model.NewPayBreakdown(userData.Position.Salary, nil)),
+		},
+		{
+			name: "should get some error while resolve salary",
+			req: &request.WithdrawRequest{
+				ID:       1,
+				SecretID: "asdjksakdas",
+			},
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			salaryErr:         errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo       mocks.UserRepository
+				positionMockRepo   mocks.PositionRepository
+				companyMockRepo    mocks.CompanyRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+				transactorMock     mocks.Transactor
+				attemptMockRepo    mocks.SecretAttemptRepository
+				salaryMockRepo     mocks.SalaryVersionRepository
+				componentMockRepo  mocks.PayComponentRepository
+				payslipMockRepo    mocks.PayslipRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, &salaryMockRepo, &componentMockRepo,
+				&companyMockRepo, &withdrawalMockRepo, &payslipMockRepo, &transactorMock, &attemptMockRepo, nil, lockout, flatTax{},
+				contributionRules)
+
+			user := userData
+			if test.user != nil {
+				user = test.user
+			}
+
+			attemptMockRepo.On("CountByIP", ctx, "", mock.AnythingOfType("time.Time")).Return(int64(0), nil).Once()
+			userMockRepo.On("FindByID", ctx, test.req.ID).Return(user, test.userRepoErr).Once()
+			attemptMockRepo.On("Create", ctx, &model.SecretAttempt{UserID: userData.ID}).Return(nil).Once()
+			userMockRepo.On("RegisterFailedSecret", ctx, userData.ID, lockout.MaxAttempts, mock.AnythingOfType("time.Time")).
+				Return(nil).Once()
+			userMockRepo.On("UpdateByID", ctx, userData.ID, mock.MatchedBy(func(user *model.User) bool {
+				return user.SecretHashed() && user.CheckSecret(test.req.SecretID)
+			})).Return(userData, test.rehashErr).Once()
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			company := *companyData
+			if test.shortBalance {
+				// enough for the net pay of 106320 but not for the cost of 124800
+				company.Balance = 110000
+			}
+			companyMockRepo.On("GetForUpdate", ctx).Return(&company, test.companyRepoErr).Once()
+			withdrawalMockRepo.On("FindOverlapping", ctx, userData.ID, period).
+				Return(test.withdrawal, test.findWithdrawalErr).Once()
+			salary := 120000
+			if test.salary != 0 {
+				salary = test.salary
+			}
+			// the salary is looked up at the cut-off of the period, whichever day it is withdrawn on
+			salaryMockRepo.On("FindEffective", ctx, userData.PositionID, period.SalaryCutoff()).
+				Return(&model.SalaryVersion{Salary: 120000}, test.salaryErr).Once()
+			if test.raisedMidPeriod {
+				raisedFrom := period.Start.AddDate(0, 0, 9)
+				salaryMockRepo.On("FindEffective", ctx, userData.PositionID, mock.MatchedBy(func(until time.Time) bool {
+					return until.After(raisedFrom)
+				})).Return(&model.SalaryVersion{Salary: 150000}, nil).Once()
+			}
+			componentMockRepo.On("FetchByUser", ctx, userData.ID).Return(test.components, test.componentErr).Once()
+			withdrawalMockRepo.On("FetchByUserBetween", ctx, userData.ID, period.TaxYearStart(), period.Start).
+				Return([]*model.Withdrawal{}, test.yearWithdrawalsErr).Once()
+			pay := withheld(model.NewPayBreakdown(salary, test.components))
+			amount := pay.NetPay
+			if test.shortBalance {
+				amount = pay.Cost()
+			}
+			companyMockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:     amount,
+				Note:       userData.Name + " withdraw salary ",
+				Category:   model.TransactionCategorySalaryWithdrawal,
+				Reference:  "SAL-" + period.Key + "-1",
+				UserID:     &userData.ID,
+				PositionID: &userData.PositionID,
+			}).Return(test.debitErr).Once()
+			withdrawal := *withdrawalData
+			withdrawal.Amount = pay.NetPay
+			withdrawal.GrossPay = pay.GrossPay
+			withdrawal.Tax = pay.Tax
+			withdrawal.TaxableBenefits = pay.EmployerContributions
+			withdrawalMockRepo.On("Create", ctx, &withdrawal).Return(&withdrawal, test.createWithdrawalErr).Once()
+			payslip := &model.Payslip{
+				UserID:         userData.ID,
+				Period:         period.Key,
+				PeriodStart:    period.Start,
+				PeriodEnd:      period.End,
+				EmployeeName:   userData.Name,
+				PositionName:   userData.Position.Name,
+				CompanyName:    companyData.Name,
+				CompanyAddress: companyData.Address,
+				Pay:            *pay,
+			}
+			payslipMockRepo.On("Create", ctx, payslip).Return(payslip, test.createPayslipErr).Once()
+			companyMockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:     pay.Tax,
+				Note:       userData.Name + " income tax withheld",
+				Category:   model.TransactionCategoryTax,
+				Reference:  "TAX-" + period.Key + "-1",
+				UserID:     &userData.ID,
+				PositionID: &userData.PositionID,
+			}).Return(test.taxDebitErr).Once()
+			companyMockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:     pay.EmployeeContributions,
+				Note:       userData.Name + " employee contributions",
+				Category:   model.TransactionCategoryEmployeeContribution,
+				Reference:  "BPJS-EE-" + period.Key + "-1",
+				UserID:     &userData.ID,
+				PositionID: &userData.PositionID,
+			}).Return(nil).Once()
+			companyMockRepo.On("DebitBalance", ctx, &model.Transaction{
+				Amount:     pay.EmployerContributions,
+				Note:       userData.Name + " employer contributions",
+				Category:   model.TransactionCategoryEmployerContribution,
+				Reference:  "BPJS-ER-" + period.Key + "-1",
+				UserID:     &userData.ID,
+				PositionID: &userData.PositionID,
+			}).Return(test.employerDebitErr).Once()
+
+			res, err := useCase.WithdrawSalary(ctx, test.req)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedPay, res)
+			if test.user == nil {
+				userMockRepo.AssertNotCalled(t, "UpdateByID", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if test.shortBalance {
+				companyMockRepo.AssertNumberOfCalls(t, "DebitBalance", 1)
+				withdrawalMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWithdrawSalaryLockout(t *testing.T) {
+	ctx := context.Background()
+	lockout := model.LockoutPolicy{MaxAttempts: 3, IPMaxAttempts: 10, Cooldown: time.Minute}
+	lockedUntil := time.Now().Add(time.Minute)
+	expiredAt := time.Now().Add(-time.Minute)
+	userData := model.User{ID: 1, Name: "user", PositionID: 1}
+	assert.NoError(t, userData.SetSecret("asdjksakdas"))
+	tests := []struct {
+		name           string
+		secretID       string
+		ipFailures     int64
+		countErr       error
+		findErr        error
+		attempts       int
+		lockedUntil    *time.Time
+		resetErr       error
+		registerErr    error
+		expectRegister bool
+		expectReset    bool
+		expectedErr    error
+	}{
+		{
+			name:        "should refuse client with too many failures",
+			secretID:    "asdjksakdas",
+			ipFailures:  10,
+			expectedErr: model.ErrTooManyAttempts,
+		},
+		{
+			name:        "should get some error while count failures",
+			secretID:    "asdjksakdas",
+			countErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+		{
+			name:        "should count unknown employee id as a failure of the client",
+			secretID:    "asdjksakdas",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should refuse client with too many failures on unknown employee ids",
+			secretID:    "asdjksakdas",
+			ipFailures:  10,
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: model.ErrTooManyAttempts,
+		},
+		{
+			name:        "should refuse locked employee even with valid secret id",
+			secretID:    "asdjksakdas",
+			attempts:    3,
+			lockedUntil: &lockedUntil,
+			expectedErr: model.ErrEmployeeLocked,
+		},
+		{
+			name:           "should count invalid secret id",
+			secretID:       "xxx-xxx",
+			attempts:       1,
+			expectRegister: true,
+			expectedErr:    errors.New("secret id not valid"),
+		},
+		{
+			name:           "should get some error while count invalid secret id",
+			secretID:       "xxx-xxx",
+			registerErr:    errors.New("some error"),
+			expectRegister: true,
+			expectedErr:    errors.New("some error"),
+		},
+		{
+			name:           "should start over after lock expired",
+			secretID:       "xxx-xxx",
+			attempts:       3,
+			lockedUntil:    &expiredAt,
+			expectReset:    true,
+			expectRegister: true,
+			expectedErr:    errors.New("secret id not valid"),
+		},
+		{
+			name:        "should reset failures after valid secret id",
+			secretID:    "asdjksakdas",
+			attempts:    2,
+			resetErr:    errors.New("some error"),
+			expectReset: true,
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo    mocks.UserRepository
+				attemptMockRepo mocks.SecretAttemptRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, nil, &attemptMockRepo, nil, lockout,
+				nil, nil)
+			user := userData
+			user.FailedSecretAttempts = test.attempts
+			user.LockedUntil = test.lockedUntil
+			req := &request.WithdrawRequest{ID: 1, SecretID: test.secretID, IP: "10.0.0.1"}
+
+			attemptMockRepo.On("CountByIP", ctx, "10.0.0.1", mock.AnythingOfType("time.Time")).
+				Return(test.ipFailures, test.countErr).Once()
+			if test.findErr != nil {
+				userMockRepo.On("FindByID", ctx, 1).Return(nil, test.findErr).Once()
+			} else {
+				userMockRepo.On("FindByID", ctx, 1).Return(&user, nil).Once()
+			}
+			userMockRepo.On("ResetFailedSecrets", ctx, 1).Return(test.resetErr).Once()
+			attemptMockRepo.On("Create", ctx, &model.SecretAttempt{UserID: 1, IP: "10.0.0.1"}).Return(nil).Once()
+			attemptMockRepo.On("Create", ctx, &model.SecretAttempt{IP: "10.0.0.1"}).Return(nil).Once()
+			userMockRepo.On("RegisterFailedSecret", ctx, 1, 3, mock.MatchedBy(func(until time.Time) bool {
+				return until.After(time.Now())
+			})).Return(test.registerErr).Once()
+
+			_, err := useCase.WithdrawSalary(ctx, req)
+			assert.Equal(t, test.expectedErr, err)
+
+			if test.expectRegister {
+				attemptMockRepo.AssertCalled(t, "Create", ctx, &model.SecretAttempt{UserID: 1, IP: "10.0.0.1"})
+			} else {
+				userMockRepo.AssertNotCalled(t, "RegisterFailedSecret", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if test.findErr != nil && test.ipFailures == 0 {
+				attemptMockRepo.AssertCalled(t, "Create", ctx, &model.SecretAttempt{IP: "10.0.0.1"})
+			} else {
+				attemptMockRepo.AssertNotCalled(t, "Create", ctx, &model.SecretAttempt{IP: "10.0.0.1"})
+			}
+			if test.expectReset {
+				userMockRepo.AssertCalled(t, "ResetFailedSecrets", ctx, 1)
+			} else {
+				userMockRepo.AssertNotCalled(t, "ResetFailedSecrets", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestFetchWithdrawals(t *testing.T) {
+	var (
+		userMockRepo       mocks.UserRepository
+		positionMockRepo   mocks.PositionRepository
+		companyMockRepo    mocks.CompanyRepository
+		withdrawalMockRepo mocks.WithdrawalRepository
+		transactorMock     mocks.Transactor
+	)
+	useCase := NewUserUsecase(&userMockRepo, &positionMockRepo, nil, nil, &companyMockRepo, &withdrawalMockRepo, nil,
+		&transactorMock, nil, nil, model.LockoutPolicy{}, nil, nil)
+	ctx := context.Background()
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	withdrawalData := &model.Withdrawal{
+		ID:          1,
+		UserID:      1,
+		Period:      period.Key,
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+		Amount:      100000,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	tests := []struct {
+		name              string
+		id                int
+		userRepoErr       error
+		data              []*model.Withdrawal
+		withdrawalRepoErr error
+		expectedResp      []*model.Withdrawal
+		expectedErr       error
+	}{
+		{
+			name:         "should fetch withdrawals successfully",
+			id:           1,
+			data:         []*model.Withdrawal{withdrawalData},
+			expectedResp: []*model.Withdrawal{withdrawalData},
+		},
+		{
+			name:        "should get some error while find user",
+			id:          1,
+			userRepoErr: gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:              "should get some error while fetch withdrawals",
+			id:                1,
+			withdrawalRepoErr: errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userMockRepo.On("FindByID", ctx, test.id).Return(&model.User{ID: test.id}, test.userRepoErr).Once()
+			if test.userRepoErr == nil {
+				withdrawalMockRepo.On("FetchByUser", ctx, test.id, 10, 0).Return(test.data, test.withdrawalRepoErr).Once()
+			}
+			res, err := useCase.FetchWithdrawals(ctx, test.id, 10, 0)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestResetSecret(t *testing.T) {
+	ctx := context.Background()
+	userData := &model.User{
+		ID:         1,
+		Name:       "user",
+		Email:      "x@company.com",
+		PositionID: 1,
+	}
+	tests := []struct {
+		name        string
+		userRepoErr error
+		updateErr   error
+		expectedErr error
+	}{
+		{
+			name: "should reset secret id successfully",
+		},
+		{
+			name:        "should get some error while find user",
+			userRepoErr: gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while update user",
+			updateErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo   mocks.UserRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, &transactorMock, nil, &auditMockRepo,
+				model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, userData.ID).Return(userData, test.userRepoErr).Once()
+			userMockRepo.On("UpdateByID", ctx, userData.ID, mock.MatchedBy(func(user *model.User) bool {
+				return user.SecretHashed() && user.CheckSecret("n3w-s3cret") && user.Name == ""
+			})).Return(userData, test.updateErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.After["secret_id"] == "reset"
+			})).Return(nil, nil).Once()
+
+			err := useCase.ResetSecret(ctx, userData.ID, &request.SecretResetRequest{SecretID: "n3w-s3cret"})
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				auditMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctx := context.Background()
+	lockedUntil := time.Now().Add(time.Minute)
+	lockedUser := &model.User{ID: 1, Name: "user", FailedSecretAttempts: 3, LockedUntil: &lockedUntil}
+	unlockedUser := &model.User{ID: 1, Name: "user"}
+	tests := []struct {
+		name         string
+		findErr      error
+		resetErr     error
+		expectedResp *model.User
+		expectedErr  error
+	}{
+		{
+			name:         "should unlock user successfully",
+			expectedResp: unlockedUser,
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while reset failures",
+			resetErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo   mocks.UserRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, &transactorMock, nil, &auditMockRepo,
+				model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(lockedUser, test.findErr).Once()
+			userMockRepo.On("ResetFailedSecrets", ctx, 1).Return(test.resetErr).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(unlockedUser, nil).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Before["failed_secret_attempts"] == float64(3) && log.After["failed_secret_attempts"] == float64(0) &&
+					log.Before["locked_until"] != nil && log.After["locked_until"] == nil
+			})).Return(nil, nil).Once()
+
+			res, err := useCase.Unlock(ctx, 1)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestNextWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	period := model.NewPayPeriod(model.PayPeriodMonthly, time.Now())
+	nextPeriod := model.NewPayPeriod(model.PayPeriodMonthly, period.End)
+	lockedUntil := time.Now().Add(time.Hour)
+	userData := &model.User{ID: 1, Name: "user", PositionID: 1, Position: &model.Position{ID: 1, Salary: 100000}}
+	lockedUser := &model.User{ID: 1, Name: "user", PositionID: 1, Position: &model.Position{ID: 1, Salary: 100000},
+		LockedUntil: &lockedUntil}
+	companyData := &model.Company{ID: 1, PayPeriod: model.PayPeriodMonthly}
+	tests := []struct {
+		name              string
+		user              *model.User
+		userErr           error
+		companyErr        error
+		withdrawal        *model.Withdrawal
+		findWithdrawalErr error
+		expectedEligible  bool
+		expectedPeriod    model.PayPeriod
+		expectedErr       error
+	}{
+		{
+			name:              "should be eligible when not paid in the current period",
+			user:              userData,
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			expectedEligible:  true,
+			expectedPeriod:    period,
+		},
+		{
+			name:             "should be eligible next period when already paid",
+			user:             userData,
+			withdrawal:       &model.Withdrawal{UserID: 1, Period: period.Key},
+			expectedEligible: false,
+			expectedPeriod:   nextPeriod,
+		},
+		{
+			name:              "should not be eligible while locked",
+			user:              lockedUser,
+			findWithdrawalErr: gorm.ErrRecordNotFound,
+			expectedEligible:  false,
+			expectedPeriod:    period,
+		},
+		{
+			name:        "should get some error while find user",
+			userErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while get company",
+			user:        userData,
+			companyErr:  errors.New("company data not found"),
+			expectedErr: errors.New("company data not found"),
+		},
+		{
+			name:              "should get some error while find withdrawal",
+			user:              userData,
+			findWithdrawalErr: errors.New("some error"),
+			expectedErr:       errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo       mocks.UserRepository
+				companyMockRepo    mocks.CompanyRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+				salaryMockRepo     mocks.SalaryVersionRepository
+				componentMockRepo  mocks.PayComponentRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, &salaryMockRepo, &componentMockRepo, &companyMockRepo,
+				&withdrawalMockRepo, nil, nil, nil, nil, model.LockoutPolicy{}, flatTax{}, nil)
+
+			userMockRepo.On("FindByID", ctx, 1).Return(test.user, test.userErr).Once()
+			companyMockRepo.On("Get", ctx).Return(companyData, test.companyErr).Once()
+			withdrawalMockRepo.On("FindOverlapping", ctx, 1, period).
+				Return(test.withdrawal, test.findWithdrawalErr).Once()
+			salaryMockRepo.On("FindEffective", ctx, 1, test.expectedPeriod.SalaryCutoff()).
+				Return(&model.SalaryVersion{Salary: 120000}, nil).Once()
+			componentMockRepo.On("FetchByUser", ctx, 1).Return([]*model.PayComponent{
+				{Name: "Meal", Type: model.PayComponentAllowance, Amount: 20000},
+			}, nil).Once()
+			withdrawalMockRepo.On("FetchByUserBetween", ctx, 1, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+				Return([]*model.Withdrawal{}, nil).Once()
+
+			res, err := useCase.NextWithdrawal(ctx, 1)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				assert.Nil(t, res)
+				return
+			}
+
+			assert.Equal(t, test.expectedEligible, res.Eligible)
+			assert.Equal(t, test.expectedPeriod, res.Period)
+			assert.Equal(t, 120000, res.Salary)
+			assert.Equal(t, 14000, res.Pay.Tax)
+			assert.Equal(t, 126000, res.Pay.NetPay)
+			if test.withdrawal != nil {
+				assert.Equal(t, period.End, res.NextEligibleAt)
+			}
+			if test.user.LockedUntil != nil {
+				assert.Equal(t, lockedUntil, res.NextEligibleAt)
+				assert.Equal(t, &lockedUntil, res.LockedUntil)
+			}
+		})
+	}
+}
+
+func TestSetBaseSalary(t *testing.T) {
+	ctx := context.Background()
+	baseSalary := 150000
+	userData := &model.User{ID: 1, Name: "user", PositionID: 1}
+	overriddenUser := &model.User{ID: 1, Name: "user", PositionID: 1, BaseSalary: &baseSalary}
+	tests := []struct {
+		name         string
+		findErr      error
+		updateErr    error
+		expectedResp *model.User
+		expectedErr  error
+	}{
+		{
+			name:         "should set base salary successfully",
+			expectedResp: overriddenUser,
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while update base salary",
+			updateErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo   mocks.UserRepository
+				auditMockRepo  mocks.AuditLogRepository
+				transactorMock mocks.Transactor
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, nil, &transactorMock, nil, &auditMockRepo,
+				model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(userData, test.findErr).Once()
+			userMockRepo.On("UpdateBaseSalary", ctx, 1, &baseSalary).Return(test.updateErr).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(overriddenUser, nil).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.Before["base_salary"] == nil && log.After["base_salary"] == float64(baseSalary)
+			})).Return(nil, nil).Once()
+
+			res, err := useCase.SetBaseSalary(ctx, 1, &request.BaseSalaryRequest{BaseSalary: &baseSalary})
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestAddPayComponent(t *testing.T) {
+	ctx := context.Background()
+	req := &request.PayComponentRequest{Name: "Transport", Type: model.PayComponentAllowance, Amount: 30000}
+	componentData := &model.PayComponent{ID: 1, UserID: 1, Name: "Transport", Type: model.PayComponentAllowance, Amount: 30000}
+	tests := []struct {
+		name         string
+		findErr      error
+		createErr    error
+		expectedResp *model.PayComponent
+		expectedErr  error
+	}{
+		{
+			name:         "should add pay component successfully",
+			expectedResp: componentData,
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while create pay component",
+			createErr:   errors.New("some error"),
+			expectedErr: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo      mocks.UserRepository
+				componentMockRepo mocks.PayComponentRepository
+				auditMockRepo     mocks.AuditLogRepository
+				transactorMock    mocks.Transactor
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, &componentMockRepo, nil, nil, nil, &transactorMock, nil,
+				&auditMockRepo, model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(&model.User{ID: 1}, test.findErr).Once()
+			componentMockRepo.On("Create", ctx, &model.PayComponent{
+				UserID: 1,
+				Name:   req.Name,
+				Type:   req.Type,
+				Amount: req.Amount,
+			}).Return(componentData, test.createErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.EntityType == model.AuditEntityPayComponent && log.Action == model.AuditActionCreate &&
+					log.After["amount"] == float64(30000)
+			})).Return(nil, nil).Once()
+
+			res, err := useCase.AddPayComponent(ctx, 1, req)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestRemovePayComponent(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		findErr     error
+		deleteErr   error
+		expectedErr error
+	}{
+		{
+			name: "should remove pay component successfully",
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should not remove pay component of another employee",
+			deleteErr:   gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo      mocks.UserRepository
+				componentMockRepo mocks.PayComponentRepository
+				auditMockRepo     mocks.AuditLogRepository
+				transactorMock    mocks.Transactor
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, &componentMockRepo, nil, nil, nil, &transactorMock, nil,
+				&auditMockRepo, model.LockoutPolicy{}, nil, nil)
+
+			transactorMock.On("WithinTransaction", ctx, mock.Anything).
+				Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Once()
+			userMockRepo.On("FindByID", ctx, 1).Return(&model.User{ID: 1}, test.findErr).Once()
+			componentMockRepo.On("Delete", ctx, 1, 2).Return(test.deleteErr).Once()
+			auditMockRepo.On("Create", ctx, mock.MatchedBy(func(log *model.AuditLog) bool {
+				return log.EntityID == 2 && log.Action == model.AuditActionDelete
+			})).Return(nil, nil).Once()
+
+			err := useCase.RemovePayComponent(ctx, 1, 2)
+
+			assert.Equal(t, test.expectedErr, err)
+			if test.expectedErr != nil {
+				auditMockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTaxSummary(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+	withdrawals := []*model.Withdrawal{
+		{ID: 1, UserID: 1, Period: "2024-01", GrossPay: 10000000, Tax: 200000, Amount: 9800000,
+			TaxableBenefits: 400000, DeductibleContributions: 300000},
+		{ID: 2, UserID: 1, Period: "2024-02", GrossPay: 12000000, Tax: 420000, Amount: 11580000,
+			TaxableBenefits: 480000, DeductibleContributions: 360000},
+	}
+	tests := []struct {
+		name         string
+		findErr      error
+		fetchErr     error
+		expectedResp *model.TaxSummary
+		expectedErr  error
+	}{
+		{
+			name: "should sum up the tax year successfully",
+			expectedResp: &model.TaxSummary{
+				UserID:                  1,
+				Year:                    2024,
+				TaxStatus:               model.TaxStatusK1,
+				GrossIncome:             22000000,
+				TaxableBenefits:         880000,
+				DeductibleContributions: 660000,
+				Withheld:                620000,
+				AnnualTax:               2222000,
+				Outstanding:             1602000,
+				Withdrawals:             withdrawals,
 			},
-			data: userData,
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get some error while fetch withdrawals",
+			fetchErr:    errors.New("some error"),
+			expectedErr: errors.New("some error"),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			userMockRepo.On("FindByID", ctx, test.req.ID).Return(test.data, test.userRepoErr).Once()
-			companyMockRepo.On("DebitBalance", ctx, test.data.Position.Salary, test.data.Name+" withdraw salary ").
-				Return(test.companyRepoErr).Once()
-			err := useCase.WithdrawSalary(ctx, test.req)
+			var (
+				userMockRepo       mocks.UserRepository
+				withdrawalMockRepo mocks.WithdrawalRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, &withdrawalMockRepo, nil, nil, nil, nil,
+				model.LockoutPolicy{}, flatTax{}, nil)
+
+			userMockRepo.On("FindByID", ctx, 1).Return(&model.User{ID: 1, TaxStatus: model.TaxStatusK1}, test.findErr).Once()
+			withdrawalMockRepo.On("FetchByUserBetween", ctx, 1, from, from.AddDate(1, 0, 0)).
+				Return(withdrawals, test.fetchErr).Once()
+
+			res, err := useCase.TaxSummary(ctx, 1, 2024)
+
+			assert.Equal(t, test.expectedResp, res)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+func TestPayslip(t *testing.T) {
+	ctx := context.Background()
+	payslipData := &model.Payslip{ID: 1, UserID: 1, Period: "2024-10", Pay: *model.NewPayBreakdown(100000, nil)}
+	tests := []struct {
+		name         string
+		findErr      error
+		payslipErr   error
+		expectedResp *model.Payslip
+		expectedErr  error
+	}{
+		{
+			name:         "should get payslip successfully",
+			expectedResp: payslipData,
+		},
+		{
+			name:        "should get some error while find user",
+			findErr:     gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name:        "should get not found error for unpaid period",
+			payslipErr:  gorm.ErrRecordNotFound,
+			expectedErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				userMockRepo    mocks.UserRepository
+				payslipMockRepo mocks.PayslipRepository
+			)
+			useCase := NewUserUsecase(&userMockRepo, nil, nil, nil, nil, nil, &payslipMockRepo, nil, nil, nil,
+				model.LockoutPolicy{}, nil, nil)
+
+			userMockRepo.On("FindByID", ctx, 1).Return(&model.User{ID: 1}, test.findErr).Once()
+			var payslip *model.Payslip
+			if test.payslipErr == nil {
+				payslip = payslipData
+			}
+			payslipMockRepo.On("FindByUserAndPeriod", ctx, 1, "2024-10").Return(payslip, test.payslipErr).Once()
+
+			res, err := useCase.Payslip(ctx, 1, "2024-10")
+
+			assert.Equal(t, test.expectedResp, res)
 			assert.Equal(t, test.expectedErr, err)
 		})
 	}